@@ -16,6 +16,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// nolint: gochecknoglobals
+var (
+	pick    string
+	verbose bool
+)
+
 // nolint: gochecknoglobals
 // rootCmd represents the root command
 var rootCmd = &cobra.Command{
@@ -35,7 +41,14 @@ var rootCmd = &cobra.Command{
 		utils.Must(err)
 		utils.Must(os.Chdir(cloneDirectory))
 
-		buildCommand, err := discover.ChooseBuildCommand(name, config.GetConfig())
+		cfg, err := config.GetConfig()
+		utils.Must(err)
+
+		// every clone is new, so there is nothing to reuse from the cache
+		// and no reason to remember the choice
+		buildCommand, err := discover.ChooseBuildCommand(name, cfg, discover.Options{
+			Pick: pick, NoCache: true, NoHistory: true, Verbose: verbose,
+		})
 		utils.Must(err)
 
 		utils.Must(utils.RunCommand(buildCommand[0], buildCommand[1:]...))
@@ -47,5 +60,7 @@ var rootCmd = &cobra.Command{
 }
 
 func main() {
+	rootCmd.Flags().StringVar(&pick, "pick", "", "candidate number or path to use without asking")
+	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "list paths skipped by discovery")
 	cobra.CheckErr(rootCmd.Execute())
 }
@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 ant1k9 <ant1k9@protonmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package add
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/ant1k9/auto-launcher/internal/pkg/discover"
+	"github.com/ant1k9/auto-launcher/internal/pkg/runfile"
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
+)
+
+// nolint: gochecknoglobals
+var (
+	description string
+	dir         string
+	env         map[string]string
+	local       bool
+	pick        string
+)
+
+// nolint: gochecknoglobals
+// addCmd represents the add command
+var Cmd = &cobra.Command{
+	Use:   "add <target>",
+	Short: "Discover an executable and add it as a named launcher target",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, err := config.GetConfig()
+		utils.Must(err)
+
+		command, err := discover.ChooseCommand(cfg, discover.Options{Pick: pick})
+		utils.Must(err)
+		if command == "" {
+			return
+		}
+
+		path, err := findRunFile()
+		utils.Must(err)
+
+		file, err := runfile.Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		utils.Must(err)
+
+		// the command is discovered here, so it runs here from a parent run file
+		if dir == "" {
+			dir, err = relativeToRunFile(path)
+			utils.Must(err)
+		}
+		if dir == "." {
+			dir = ""
+		}
+
+		utils.Must(file.Add(args[0], runfile.Target{
+			Command:     command,
+			Dir:         dir,
+			Env:         env,
+			Description: description,
+		}))
+		utils.Must(file.Write(path))
+	},
+}
+
+// relativeToRunFile is the working directory relative to the run file
+func relativeToRunFile(path string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(filepath.Dir(path), wd)
+}
+
+// findRunFile returns the nearest run file like the launcher does, a new one
+// is created in the current directory if there is none or with --local
+func findRunFile() (string, error) {
+	if local {
+		return discover.RunFile, nil
+	}
+
+	path, err := runfile.Find(".", discover.RunFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return discover.RunFile, nil
+	}
+	return path, err
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&description, "description", "d", "", "target description")
+	Cmd.Flags().StringVar(&dir, "dir", "", "working directory relative to the run file")
+	Cmd.Flags().BoolVarP(&local, "local", "l", false, "add to the run file in the current directory only")
+	Cmd.Flags().StringVar(&pick, "pick", "", "candidate number or path to use without asking")
+	Cmd.Flags().StringToStringVarP(&env, "env", "e", nil, "environment variables, e.g. -e PORT=8080")
+}
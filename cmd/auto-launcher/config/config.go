@@ -0,0 +1,79 @@
+/*
+Copyright © 2021 ant1k9 <ant1k9@protonmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+
+	internalconfig "github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
+)
+
+// nolint: gochecknoglobals
+var withOrigin bool
+
+// nolint: gochecknoglobals
+// configCmd represents the config command
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect auto-launcher configuration",
+}
+
+// nolint: gochecknoglobals
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration merged from all config files",
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, origins, err := internalconfig.Load()
+		utils.Must(err)
+
+		if !withOrigin {
+			utils.Must(toml.NewEncoder(os.Stdout).Encode(cfg))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) // nolint: gomnd
+		for _, setting := range cfg.Settings(origins) {
+			fmt.Fprintf(w, "%s = %s\t# %s\n", setting.Key, setting.Value, strings.Join(setting.Origins, ", "))
+		}
+		utils.Must(w.Flush())
+	},
+}
+
+// nolint: gochecknoglobals
+var validateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check config files, by default all files of the effective configuration",
+	Run: func(_ *cobra.Command, args []string) {
+		if err := internalconfig.Validate(args...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("ok")
+	},
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&withOrigin, "origin", false, "print the file every value came from")
+	Cmd.AddCommand(showCmd)
+	Cmd.AddCommand(validateCmd)
+}
@@ -16,9 +16,13 @@ limitations under the License.
 package edit
 
 import (
+	"errors"
+	"os"
+
 	"github.com/spf13/cobra"
 
 	"github.com/ant1k9/auto-launcher/internal/pkg/discover"
+	"github.com/ant1k9/auto-launcher/internal/pkg/runfile"
 	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
 )
 
@@ -26,8 +30,13 @@ import (
 // editCmd represents the edit command
 var Cmd = &cobra.Command{
 	Use:   "edit",
-	Short: "Edit launcher command",
+	Short: "Edit the nearest launcher command",
 	Run: func(_ *cobra.Command, args []string) {
-		utils.Must(utils.RunCommand("/usr/bin/env", "vim", discover.RunFile))
+		path, err := runfile.Find(".", discover.RunFile)
+		if errors.Is(err, os.ErrNotExist) {
+			path, err = discover.RunFile, nil
+		}
+		utils.Must(err)
+		utils.Must(utils.RunCommand("/usr/bin/env", "vim", path))
 	},
 }
@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 ant1k9 <ant1k9@protonmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/ant1k9/auto-launcher/internal/pkg/discover"
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
+)
+
+// nolint: gochecknoglobals
+var (
+	asJSON  bool
+	asPlain bool
+	noCache bool
+	verbose bool
+)
+
+// nolint: gochecknoglobals
+// listCmd represents the list command
+var Cmd = &cobra.Command{
+	Use:   "list",
+	Short: "List discovered executables and their launch commands",
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, err := config.GetConfig()
+		utils.Must(err)
+
+		candidates, err := discover.ListCandidates(cfg, discover.Options{NoCache: noCache, Verbose: verbose})
+		utils.Must(err)
+
+		switch {
+		case asJSON:
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			utils.Must(encoder.Encode(candidates))
+		case asPlain:
+			for _, c := range candidates {
+				fmt.Printf("%s\t%s\t%s\n", c.Extension, c.Path, oneLine(c.Command))
+			}
+		default:
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) // nolint: gomnd
+			fmt.Fprintln(w, "#\tLANGUAGE\tPATH\tCOMMAND\tDESCRIPTION")
+			for _, c := range candidates {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", c.Number, c.Extension, c.Path, oneLine(c.Command), c.Description)
+			}
+			utils.Must(w.Flush())
+		}
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&asJSON, "json", false, "print candidates as JSON")
+	Cmd.Flags().BoolVar(&asPlain, "plain", false, "print tab separated candidates without a header")
+	Cmd.Flags().BoolVar(&noCache, "no-cache", false, "discover without the cache of previous runs")
+	Cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "list paths skipped by discovery")
+}
+
+// oneLine joins multiline commands so that every candidate takes one line
+func oneLine(command string) string {
+	return strings.ReplaceAll(command, "\n", "; ")
+}
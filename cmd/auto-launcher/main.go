@@ -16,38 +16,84 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"github.com/ant1k9/auto-launcher/cmd/auto-launcher/add"
+	"github.com/ant1k9/auto-launcher/cmd/auto-launcher/cache"
+	configcmd "github.com/ant1k9/auto-launcher/cmd/auto-launcher/config"
 	"github.com/ant1k9/auto-launcher/cmd/auto-launcher/edit"
+	"github.com/ant1k9/auto-launcher/cmd/auto-launcher/list"
 	"github.com/ant1k9/auto-launcher/cmd/auto-launcher/rm"
 	"github.com/ant1k9/auto-launcher/internal/config"
 	"github.com/ant1k9/auto-launcher/internal/pkg/discover"
+	"github.com/ant1k9/auto-launcher/internal/pkg/runfile"
 	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
 )
 
+// nolint: gochecknoglobals
+var (
+	local   bool
+	noCache bool
+	pick    string
+	verbose bool
+)
+
 // nolint: gochecknoglobals
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "auto-launcher",
+	Use:   "auto-launcher [target] [args...]",
 	Short: "Auto discover and launch executable files",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, err := os.Stat(discover.RunFile)
-		if os.IsNotExist(err) {
-			err = discover.ChooseExecutable(config.GetConfig())
+		path, err := findRunFile()
+		if errors.Is(err, os.ErrNotExist) {
+			var cfg config.Config
+			if cfg, err = config.GetConfig(); err == nil {
+				path, err = discover.RunFile, discover.ChooseExecutable(
+					cfg, discover.Options{Pick: pick, NoCache: noCache, Verbose: verbose},
+				)
+			}
 		}
 		utils.Must(err)
 
-		utils.Must(utils.RunCommand(
-			"/usr/bin/env",
-			append([]string{"bash", discover.RunFile}, args...)...),
-		)
+		file, err := runfile.Read(path)
+		utils.Must(err)
+
+		_, target, args, err := file.Resolve(args)
+		utils.Must(err)
+		utils.Must(target.Exec(filepath.Dir(path), args))
 	},
 }
 
-func main() {
+// findRunFile returns the nearest run file from the working directory
+// upwards, or only the local one with --local
+func findRunFile() (string, error) {
+	if local {
+		_, err := os.Stat(discover.RunFile)
+		return discover.RunFile, err
+	}
+	return runfile.Find(".", discover.RunFile)
+}
+
+func init() {
+	// arguments after the target name are the target's, even flags
+	rootCmd.Flags().SetInterspersed(false)
+	rootCmd.Flags().BoolVarP(&local, "local", "l", false, "use or create the run file in the current directory only")
+	rootCmd.Flags().StringVar(&pick, "pick", "", "candidate number or path to use without asking")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "discover without the cache of previous runs")
+	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "list paths skipped by discovery")
+	rootCmd.AddCommand(add.Cmd)
+	rootCmd.AddCommand(cache.Cmd)
+	rootCmd.AddCommand(configcmd.Cmd)
 	rootCmd.AddCommand(edit.Cmd)
+	rootCmd.AddCommand(list.Cmd)
 	rootCmd.AddCommand(rm.Cmd)
+}
+
+func main() {
 	cobra.CheckErr(rootCmd.Execute())
 }
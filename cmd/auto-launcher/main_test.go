@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetArgs(t *testing.T) {
+	defer func() { local = false }()
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantArgs  []string
+		wantLocal bool
+	}{
+		{
+			name:     "flags after the target",
+			args:     []string{"worker", "--verbose", "-l", "--", "-x"},
+			wantArgs: []string{"worker", "--verbose", "-l", "--", "-x"},
+		},
+		{
+			name:      "flags before the target",
+			args:      []string{"--local", "worker", "--local"},
+			wantArgs:  []string{"worker", "--local"},
+			wantLocal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local = false
+			cmd, args, err := rootCmd.Find(tt.args)
+			require.NoError(t, err)
+			require.Equal(t, rootCmd, cmd)
+
+			require.NoError(t, cmd.ParseFlags(args))
+			assert.Equal(t, tt.wantArgs, cmd.Flags().Args())
+			assert.Equal(t, tt.wantLocal, local)
+		})
+	}
+}
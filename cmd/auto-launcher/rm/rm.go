@@ -16,19 +16,44 @@ limitations under the License.
 package rm
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ant1k9/auto-launcher/internal/pkg/discover"
+	"github.com/ant1k9/auto-launcher/internal/pkg/runfile"
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
 )
 
 // nolint: gochecknoglobals
 // rmCmd represents the rm command
 var Cmd = &cobra.Command{
-	Use:   "rm",
-	Short: "Remove launcher command",
+	Use:   "rm [target...]",
+	Short: "Remove the nearest launcher command or some of its targets",
 	Run: func(_ *cobra.Command, args []string) {
-		_ = os.Remove(discover.RunFile)
+		path, err := runfile.Find(".", discover.RunFile)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		utils.Must(err)
+
+		if len(args) == 0 {
+			_ = os.Remove(path)
+			return
+		}
+
+		file, err := runfile.Read(path)
+		utils.Must(err)
+		for _, name := range args {
+			// removing every target removes the file
+			err := file.Remove(name)
+			if errors.Is(err, runfile.ErrLastTarget) {
+				utils.Must(os.Remove(path))
+				return
+			}
+			utils.Must(err)
+		}
+		utils.Must(file.Write(path))
 	},
 }
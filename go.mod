@@ -3,12 +3,15 @@ module github.com/ant1k9/auto-launcher
 go 1.19
 
 require (
-	github.com/BurntSushi/toml v0.4.1
+	github.com/BurntSushi/toml v1.3.2
+	github.com/bmatcuk/doublestar/v4 v4.6.1
 	github.com/gizak/termui/v3 v3.1.0
 	github.com/go-git/go-git/v5 v5.5.1
 	github.com/otiai10/copy v1.9.0
 	github.com/spf13/cobra v1.2.1
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/term v0.2.0
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
@@ -37,5 +40,4 @@ require (
 	golang.org/x/net v0.2.0 // indirect
 	golang.org/x/sys v0.2.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
@@ -1,32 +1,260 @@
 package config
 
 import (
-	"log"
+	"errors"
 	"os"
+	"path/filepath"
 
 	"github.com/BurntSushi/toml"
 )
 
-const envConfigPath = "AUTO_LAUNCHER_CONFIG_PATH"
+const (
+	envConfigPath = "AUTO_LAUNCHER_CONFIG_PATH"
+
+	// ProjectConfigFile is searched for from the working directory upwards
+	ProjectConfigFile = ".auto-launcher.toml"
+
+	// DefaultOrigin is the origin of built-in values
+	DefaultOrigin = "default"
+)
+
+// nolint: gochecknoglobals
+var (
+	systemConfigPath = "/etc/auto-launcher/config.toml"
+
+	// ContainerEngines are the supported values of container_engine
+	ContainerEngines = []string{"docker", "podman"}
+)
 
 type (
 	Config struct {
 		// SkipPaths is a list of directories where auto-launcher will not
-		// search for executables
+		// search for executables. A pattern without a slash matches base
+		// names, e.g. "node_modules" or "*_test.go", other patterns match paths
+		// relative to the search root, e.g. "examples/*/vendor" or "third_party/**"
 		SkipPaths []string `toml:"skip_paths"`
+		// IncludePaths limits the search to matching files and directories,
+		// all paths are searched if it is empty
+		IncludePaths []string `toml:"include_paths"`
+		// MaxDepth limits how deep the search goes, 1 searches the files of
+		// the search root only and 0 does not limit it
+		MaxDepth int `toml:"max_depth"`
+		// MaxFileSize skips larger files in bytes, 0 does not limit it
+		MaxFileSize int64 `toml:"max_file_size"`
+		// RespectGitignore skips paths ignored by .gitignore and .ignore files
+		RespectGitignore bool `toml:"respect_gitignore"`
+		// FollowSymlinks searches symlinked directories too, links to the
+		// directories searched already are skipped
+		FollowSymlinks bool `toml:"follow_symlinks"`
+		// AutoPickMargin is the score lead that lets the best candidate be
+		// chosen without asking, zero disables it
+		AutoPickMargin int `toml:"auto_pick_margin"`
+		// ContainerEngine builds and runs Dockerfiles and compose files, one
+		// of ContainerEngines, docker if it is empty
+		ContainerEngine string `toml:"container_engine"`
+		// Picker is a command that reads candidates from stdin, one per line,
+		// and prints the chosen one, e.g. "fzf". The built-in chooser is used
+		// if it is empty.
+		Picker string `toml:"picker"`
+		// Detectors describe additional kinds of entry points, they are
+		// matched before the built-in ones
+		Detectors []Detector `toml:"detectors"`
+		// Commands override command templates of detectors by their names,
+		// e.g. ".cpp" or "Makefile"
+		Commands map[string]Commands `toml:"commands"`
+	}
+
+	Detector struct {
+		// Name groups the detected files, it is shown as their language
+		Name string `toml:"name"`
+		// Glob is a pattern matched against a file name, e.g. "*.ts"
+		Glob string `toml:"glob"`
+		// Content is an optional regular expression a file has to contain
+		Content string `toml:"content,omitempty"`
+		Commands
 	}
+
+	// Commands are bash command templates. The placeholders are {path} of
+	// the entry point, its {dir}, the {target} in the file if it has several,
+	// the project {name} and launcher {args}.
+	Commands struct {
+		Run   string `toml:"run,omitempty"`
+		Build string `toml:"build,omitempty"`
+	}
+
+	// layer is a single config file. Lists replace values of the previous
+	// layers, the extra_ prefixed lists are appended to them instead.
+	layer struct {
+		Config
+		ExtraSkipPaths    []string `toml:"extra_skip_paths"`
+		ExtraIncludePaths []string `toml:"extra_include_paths"`
+	}
+
+	// Origins maps config keys to the files their values came from
+	Origins map[string][]string
 )
 
-func GetConfig() Config {
-	var cfg Config
+// GetConfig merges config layers, from the lowest priority to the highest:
+// built-in defaults, the system config, $XDG_CONFIG_HOME/auto-launcher,
+// the nearest ProjectConfigFile and the file from AUTO_LAUNCHER_CONFIG_PATH.
+// A broken config file is an error, see Validate.
+func GetConfig() (Config, error) {
+	cfg, _, err := Load()
+	return cfg, err
+}
+
+// Load is GetConfig that also reports where every value came from
+func Load() (Config, Origins, error) {
+	cfg, origins := defaultConfig(), Origins{
+		"skip_paths":        {DefaultOrigin},
+		"respect_gitignore": {DefaultOrigin},
+		"follow_symlinks":   {DefaultOrigin},
+		"auto_pick_margin":  {DefaultOrigin},
+		"max_file_size":     {DefaultOrigin},
+	}
+
+	paths, err := layerPaths()
+	if err != nil {
+		return cfg, origins, err
+	}
+
+	for _, path := range paths {
+		l, md, err := readLayer(path)
+		if err != nil {
+			return defaultConfig(), nil, err
+		}
+		cfg.merge(l, md, path, origins)
+	}
+	return cfg, origins, nil
+}
+
+// layerPaths returns existing config files in the order they are applied
+func layerPaths() ([]string, error) {
+	candidates := []string{systemConfigPath}
+	if dir := userConfigDir(); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "auto-launcher", "config.toml"))
+	}
+	if path, ok := findProjectConfig(); ok {
+		candidates = append(candidates, path)
+	}
+
+	paths := make([]string, 0, len(candidates)+1)
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	// the explicitly set config must exist
 	if path := os.Getenv(envConfigPath); path != "" {
-		if _, err := toml.DecodeFile(path, &cfg); err != nil {
-			log.Printf("error parsing config file: %s", err)
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
 		}
-		return cfg
+		paths = append(paths, path)
 	}
+	return paths, nil
+}
 
-	return defaultConfig()
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config")
+	}
+	return ""
+}
+
+func findProjectConfig() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func (cfg *Config) merge(l layer, md toml.MetaData, origin string, origins Origins) {
+	if md.IsDefined("skip_paths") {
+		cfg.SkipPaths = l.SkipPaths
+		origins["skip_paths"] = []string{origin}
+	}
+	if md.IsDefined("extra_skip_paths") {
+		cfg.SkipPaths = append(cfg.SkipPaths, l.ExtraSkipPaths...)
+		origins["skip_paths"] = append(origins["skip_paths"], origin)
+	}
+	if md.IsDefined("include_paths") {
+		cfg.IncludePaths = l.IncludePaths
+		origins["include_paths"] = []string{origin}
+	}
+	if md.IsDefined("extra_include_paths") {
+		cfg.IncludePaths = append(cfg.IncludePaths, l.ExtraIncludePaths...)
+		origins["include_paths"] = append(origins["include_paths"], origin)
+	}
+	if md.IsDefined("auto_pick_margin") {
+		cfg.AutoPickMargin = l.AutoPickMargin
+		origins["auto_pick_margin"] = []string{origin}
+	}
+	if md.IsDefined("max_depth") {
+		cfg.MaxDepth = l.MaxDepth
+		origins["max_depth"] = []string{origin}
+	}
+	if md.IsDefined("max_file_size") {
+		cfg.MaxFileSize = l.MaxFileSize
+		origins["max_file_size"] = []string{origin}
+	}
+	if md.IsDefined("container_engine") {
+		cfg.ContainerEngine = l.ContainerEngine
+		origins["container_engine"] = []string{origin}
+	}
+	if md.IsDefined("picker") {
+		cfg.Picker = l.Picker
+		origins["picker"] = []string{origin}
+	}
+	if md.IsDefined("respect_gitignore") {
+		cfg.RespectGitignore = l.RespectGitignore
+		origins["respect_gitignore"] = []string{origin}
+	}
+	if md.IsDefined("follow_symlinks") {
+		cfg.FollowSymlinks = l.FollowSymlinks
+		origins["follow_symlinks"] = []string{origin}
+	}
+
+	// detectors and commands are merged by their names
+	for _, d := range l.Detectors {
+		cfg.Detectors = replaceDetector(cfg.Detectors, d)
+		origins["detectors."+d.Name] = []string{origin}
+	}
+	for name, commands := range l.Commands {
+		if cfg.Commands == nil {
+			cfg.Commands = make(map[string]Commands)
+		}
+		cfg.Commands[name] = commands
+		origins["commands."+name] = []string{origin}
+	}
+}
+
+func replaceDetector(detectors []Detector, d Detector) []Detector {
+	for idx := range detectors {
+		if detectors[idx].Name == d.Name {
+			detectors[idx] = d
+			return detectors
+		}
+	}
+	return append(detectors, d)
 }
 
 func defaultConfig() Config {
@@ -36,6 +264,9 @@ func defaultConfig() Config {
 			"test",
 			"target",
 			".ccls",
+			"node_modules",
 		},
+		AutoPickMargin: 50,      // nolint: gomnd
+		MaxFileSize:    1 << 20, // nolint: gomnd
 	}
 }
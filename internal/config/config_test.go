@@ -4,15 +4,30 @@ import (
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// setConfigEnv points the config layers to the files of the test, the
+// system config of the machine is left out
+func setConfigEnv(t *testing.T, configPath, configHome string) {
+	t.Helper()
+	t.Setenv(envConfigPath, configPath)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	system := systemConfigPath
+	systemConfigPath = path.Join(t.TempDir(), "config.toml")
+	t.Cleanup(func() { systemConfigPath = system })
+}
+
 func TestGetConfig(t *testing.T) {
 	want := Config{
-		SkipPaths: []string{".ccls", "node-modules"},
+		SkipPaths:      []string{".ccls", "node-modules"},
+		AutoPickMargin: 50,
+		MaxFileSize:    1 << 20,
 	}
 
 	tmpFile, err := ioutil.TempFile(os.TempDir(), "test")
@@ -26,6 +41,145 @@ func TestGetConfig(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	os.Setenv(envConfigPath, tmpFile.Name())
-	assert.EqualValues(t, want, GetConfig())
+	setConfigEnv(t, tmpFile.Name(), t.TempDir())
+	cfg, err := GetConfig()
+	require.NoError(t, err)
+	assert.EqualValues(t, want, cfg)
+}
+
+func TestGetConfigDetectors(t *testing.T) {
+	want := Config{
+		SkipPaths:      []string{".git"},
+		AutoPickMargin: 50,
+		MaxFileSize:    1 << 20,
+		Detectors: []Detector{{
+			Name:     "typescript",
+			Glob:     "*.ts",
+			Content:  `main\(`,
+			Commands: Commands{Run: "tsx {path} {args}"},
+		}},
+		Commands: map[string]Commands{
+			".cpp": {Run: "g++ -std=c++20 -o main {dir}/*.cpp && ./main {args}"},
+		},
+	}
+
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "test")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	err = ioutil.WriteFile(
+		tmpFile.Name(),
+		[]byte(`
+skip_paths = [ ".git" ]
+
+[[detectors]]
+name = "typescript"
+glob = "*.ts"
+content = 'main\('
+run = "tsx {path} {args}"
+
+[commands.".cpp"]
+run = "g++ -std=c++20 -o main {dir}/*.cpp && ./main {args}"
+`),
+		fs.ModePerm,
+	)
+	require.NoError(t, err)
+
+	setConfigEnv(t, tmpFile.Name(), t.TempDir())
+	cfg, err := GetConfig()
+	require.NoError(t, err)
+	assert.EqualValues(t, want, cfg)
+}
+
+func TestLoadLayers(t *testing.T) {
+	rootPath, err := ioutil.TempDir(os.TempDir(), "config-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootPath)
+
+	userConfig := path.Join(rootPath, "xdg", "auto-launcher", "config.toml")
+	projectConfig := path.Join(rootPath, "project", ProjectConfigFile)
+	envConfig := path.Join(rootPath, "env.toml")
+	workDir := path.Join(rootPath, "project", "internal", "pkg")
+
+	require.NoError(t, os.MkdirAll(path.Dir(userConfig), 0755))
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	for p, content := range map[string]string{
+		userConfig: `
+skip_paths = [ ".git" ]
+picker = "fzf"
+
+[commands.".cpp"]
+run = "clang++ {path}"
+
+[[detectors]]
+name = "typescript"
+glob = "*.ts"
+run = "ts-node {path}"
+`,
+		projectConfig: `
+extra_skip_paths = [ "node_modules" ]
+respect_gitignore = true
+
+[[detectors]]
+name = "typescript"
+glob = "*.ts"
+run = "tsx {path}"
+`,
+		envConfig: `
+extra_skip_paths = [ "vendor" ]
+include_paths = [ "cmd/**" ]
+`,
+	} {
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), fs.ModePerm))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	defer os.Chdir(wd) // nolint: errcheck
+
+	setConfigEnv(t, envConfig, path.Join(rootPath, "xdg"))
+
+	// the working directory may be resolved through symlinks
+	projectWorkDir, err := os.Getwd()
+	require.NoError(t, err)
+	projectConfig = path.Join(path.Dir(path.Dir(projectWorkDir)), ProjectConfigFile)
+
+	cfg, origins, err := Load()
+	require.NoError(t, err)
+
+	assert.EqualValues(t, Config{
+		SkipPaths:        []string{".git", "node_modules", "vendor"},
+		IncludePaths:     []string{"cmd/**"},
+		RespectGitignore: true,
+		AutoPickMargin:   50,
+		MaxFileSize:      1 << 20,
+		Picker:           "fzf",
+		Detectors:        []Detector{{Name: "typescript", Glob: "*.ts", Commands: Commands{Run: "tsx {path}"}}},
+		Commands:         map[string]Commands{".cpp": {Run: "clang++ {path}"}},
+	}, cfg)
+	assert.EqualValues(t, Origins{
+		"skip_paths":           {userConfig, projectConfig, envConfig},
+		"include_paths":        {envConfig},
+		"respect_gitignore":    {projectConfig},
+		"follow_symlinks":      {DefaultOrigin},
+		"auto_pick_margin":     {DefaultOrigin},
+		"max_file_size":        {DefaultOrigin},
+		"picker":               {userConfig},
+		"detectors.typescript": {projectConfig},
+		"commands..cpp":        {userConfig},
+	}, origins)
+
+	assert.EqualValues(t, []Setting{
+		{Key: "skip_paths", Value: `[".git", "node_modules", "vendor"]`, Origins: origins["skip_paths"]},
+		{Key: "include_paths", Value: `["cmd/**"]`, Origins: []string{envConfig}},
+		{Key: "respect_gitignore", Value: "true", Origins: []string{projectConfig}},
+		{Key: "follow_symlinks", Value: "false", Origins: []string{DefaultOrigin}},
+		{Key: "auto_pick_margin", Value: "50", Origins: []string{DefaultOrigin}},
+		{Key: "max_file_size", Value: "1048576", Origins: []string{DefaultOrigin}},
+		{Key: "picker", Value: `"fzf"`, Origins: []string{userConfig}},
+		{Key: "detectors.typescript.glob", Value: `"*.ts"`, Origins: []string{projectConfig}},
+		{Key: "detectors.typescript.run", Value: `"tsx {path}"`, Origins: []string{projectConfig}},
+		{Key: `commands.".cpp".run`, Value: `"clang++ {path}"`, Origins: []string{userConfig}},
+	}, cfg.Settings(origins))
 }
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Setting is a single effective config value under a dotted TOML key
+type Setting struct {
+	Key     string
+	Value   string
+	Origins []string
+}
+
+// Settings flattens the config into dotted keys with TOML values, every
+// setting keeps the files it came from
+func (cfg Config) Settings(origins Origins) []Setting {
+	var settings []Setting
+	add := func(originKey, key, value string) {
+		if value != "" {
+			settings = append(settings, Setting{Key: key, Value: value, Origins: origins[originKey]})
+		}
+	}
+
+	add("skip_paths", "skip_paths", quoteList(cfg.SkipPaths))
+	add("include_paths", "include_paths", quoteList(cfg.IncludePaths))
+	add("respect_gitignore", "respect_gitignore", strconv.FormatBool(cfg.RespectGitignore))
+	add("follow_symlinks", "follow_symlinks", strconv.FormatBool(cfg.FollowSymlinks))
+	add("auto_pick_margin", "auto_pick_margin", strconv.Itoa(cfg.AutoPickMargin))
+	if cfg.MaxDepth > 0 {
+		add("max_depth", "max_depth", strconv.Itoa(cfg.MaxDepth))
+	}
+	add("max_file_size", "max_file_size", strconv.FormatInt(cfg.MaxFileSize, 10))
+	add("container_engine", "container_engine", quote(cfg.ContainerEngine))
+	add("picker", "picker", quote(cfg.Picker))
+
+	for _, d := range cfg.Detectors {
+		originKey, prefix := "detectors."+d.Name, "detectors."+quoteKey(d.Name)+"."
+		add(originKey, prefix+"glob", quote(d.Glob))
+		add(originKey, prefix+"content", quote(d.Content))
+		add(originKey, prefix+"run", quote(d.Run))
+		add(originKey, prefix+"build", quote(d.Build))
+	}
+
+	names := make([]string, 0, len(cfg.Commands))
+	for name := range cfg.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		originKey, prefix := "commands."+name, "commands."+quoteKey(name)+"."
+		add(originKey, prefix+"run", quote(cfg.Commands[name].Run))
+		add(originKey, prefix+"build", quote(cfg.Commands[name].Build))
+	}
+
+	return settings
+}
+
+func quoteKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func quote(value string) string {
+	if value == "" {
+		return ""
+	}
+	return strconv.Quote(value)
+}
+
+func quoteList(values []string) string {
+	if values == nil {
+		return ""
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
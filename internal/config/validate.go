@@ -0,0 +1,318 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+var (
+	decodeErrorLine = regexp.MustCompile(`^toml: line (\d+):? (.*)$`)
+	tableHeader     = regexp.MustCompile(`^\[\[?([^\[\]]+)\]\]?`)
+	keyValue        = regexp.MustCompile(`^([^=#]+?)\s*=`)
+)
+
+type (
+	// ValidationError points to a problem in a config file
+	ValidationError struct {
+		Path    string
+		Line    int
+		Message string
+	}
+
+	// ValidationErrors are all problems found in config files
+	ValidationErrors []ValidationError
+
+	// keyLocator finds lines of keys in a TOML document
+	keyLocator struct {
+		entries []keyEntry
+		used    map[int]bool
+	}
+
+	// problem is an invalid value of a field
+	problem struct {
+		field   string
+		message string
+	}
+
+	keyEntry struct {
+		line  int
+		key   string
+		index int // index of the array table the key is in
+	}
+)
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks config files for syntax errors, unknown keys, wrong types
+// and invalid patterns. Without paths it checks all layers of the config.
+func Validate(paths ...string) error {
+	if len(paths) == 0 {
+		var err error
+		if paths, err = layerPaths(); err != nil {
+			return err
+		}
+	}
+
+	var errs ValidationErrors
+	for _, path := range paths {
+		_, _, err := readLayer(path)
+
+		var layerErrs ValidationErrors
+		switch {
+		case errors.As(err, &layerErrs):
+			errs = append(errs, layerErrs...)
+		case err != nil:
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// readLayer decodes a config file, problems in it are ValidationErrors
+func readLayer(path string) (layer, toml.MetaData, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return layer{}, toml.MetaData{}, err
+	}
+
+	locator := newKeyLocator(content)
+
+	var (
+		l    layer
+		errs ValidationErrors
+	)
+	md, err := toml.Decode(string(content), &l)
+	undecoded := md.Undecoded()
+	if err != nil {
+		var raw map[string]any
+		if _, rawErr := toml.Decode(string(content), &raw); rawErr != nil {
+			return l, md, ValidationErrors{decodeError(path, content, err)}
+		}
+		// a value of a wrong type stops decoding, the rest of the file is
+		// still checked key by key
+		l, undecoded, errs = decodeKeys(path, raw, locator)
+	}
+
+	for _, key := range undecoded {
+		errs = append(errs, ValidationError{
+			Path:    path,
+			Line:    locator.find(key.String(), -1),
+			Message: fmt.Sprintf("unknown key %q", key.String()),
+		})
+	}
+
+	for _, p := range l.pathProblems() {
+		errs = append(errs, ValidationError{
+			Path:    path,
+			Line:    locator.find(p.field, -1),
+			Message: p.message,
+		})
+	}
+
+	for _, p := range l.valueProblems() {
+		errs = append(errs, ValidationError{
+			Path:    path,
+			Line:    locator.find(p.field, -1),
+			Message: p.message,
+		})
+	}
+
+	for idx, d := range l.Detectors {
+		for _, p := range d.problems() {
+			errs = append(errs, ValidationError{
+				Path:    path,
+				Line:    locator.find("detectors."+p.field, idx),
+				Message: p.message,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+		return l, md, errs
+	}
+	return l, md, nil
+}
+
+// decodeKeys decodes top-level keys one by one, keys with values of wrong
+// types are left out of the layer and reported
+func decodeKeys(path string, raw map[string]any, locator *keyLocator) (layer, []toml.Key, ValidationErrors) {
+	var (
+		l         layer
+		undecoded []toml.Key
+		errs      ValidationErrors
+	)
+	for key, value := range raw {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(map[string]any{key: value}); err != nil {
+			return l, undecoded, append(errs, ValidationError{Path: path, Message: err.Error()})
+		}
+
+		var part layer
+		if _, err := toml.Decode(buf.String(), &part); err != nil {
+			errs = append(errs, ValidationError{
+				Path:    path,
+				Line:    locator.find(key, -1),
+				Message: decodeError(path, buf.Bytes(), err).Message,
+			})
+			continue
+		}
+		md, _ := toml.Decode(buf.String(), &l)
+		undecoded = append(undecoded, md.Undecoded()...)
+	}
+	return l, undecoded, errs
+}
+
+// decodeError moves the line number out of syntax and type errors
+func decodeError(path string, content []byte, err error) ValidationError {
+	if m := decodeErrorLine.FindStringSubmatch(err.Error()); len(m) > 0 {
+		line, _ := strconv.Atoi(m[1])
+		if line == 0 && strings.HasSuffix(m[2], "end of file") {
+			line = bytes.Count(bytes.TrimRight(content, "\n"), []byte("\n")) + 1
+		}
+		return ValidationError{Path: path, Line: line, Message: m[2]}
+	}
+	return ValidationError{Path: path, Message: err.Error()}
+}
+
+func (l layer) pathProblems() []problem {
+	var problems []problem
+	for field, patterns := range map[string][]string{
+		"skip_paths":          l.SkipPaths,
+		"extra_skip_paths":    l.ExtraSkipPaths,
+		"include_paths":       l.IncludePaths,
+		"extra_include_paths": l.ExtraIncludePaths,
+	} {
+		for _, pattern := range patterns {
+			if !doublestar.ValidatePattern(pattern) {
+				problems = append(problems, problem{field, fmt.Sprintf("%s: bad glob %q", field, pattern)})
+			}
+		}
+	}
+	return problems
+}
+
+func (l layer) valueProblems() []problem {
+	var problems []problem
+	if l.MaxDepth < 0 {
+		problems = append(problems, problem{"max_depth", "max_depth: must not be negative"})
+	}
+	if l.MaxFileSize < 0 {
+		problems = append(problems, problem{"max_file_size", "max_file_size: must not be negative"})
+	}
+	if l.ContainerEngine != "" && !knownEngine(l.ContainerEngine) {
+		problems = append(problems, problem{"container_engine", fmt.Sprintf(
+			"container_engine: unknown engine %q, expected one of %s",
+			l.ContainerEngine, strings.Join(ContainerEngines, ", "),
+		)})
+	}
+	return problems
+}
+
+func knownEngine(name string) bool {
+	for _, engine := range ContainerEngines {
+		if name == engine {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Detector) problems() []problem {
+	var problems []problem
+	if d.Name == "" {
+		problems = append(problems, problem{"name", "detector without a name"})
+	}
+
+	if d.Glob == "" {
+		problems = append(problems, problem{"glob", fmt.Sprintf("detector %q: empty glob", d.Name)})
+	} else if _, err := filepath.Match(d.Glob, ""); err != nil {
+		problems = append(problems, problem{"glob", fmt.Sprintf("detector %q: bad glob %q: %s", d.Name, d.Glob, err)})
+	}
+
+	if _, err := regexp.Compile(d.Content); err != nil {
+		problems = append(problems, problem{"content", fmt.Sprintf("detector %q: bad content regexp: %s", d.Name, err)})
+	}
+
+	if d.Run == "" && d.Build == "" {
+		problems = append(problems, problem{"run", fmt.Sprintf("detector %q: neither run nor build command", d.Name)})
+	}
+	return problems
+}
+
+func newKeyLocator(content []byte) *keyLocator {
+	locator := &keyLocator{used: make(map[int]bool)}
+
+	table, index, tables := "", -1, make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if m := tableHeader.FindStringSubmatch(text); len(m) > 0 {
+			table = normalizeKey(m[1])
+			index = tables[table]
+			tables[table]++
+			locator.entries = append(locator.entries, keyEntry{line: line, key: table, index: index})
+			continue
+		}
+
+		if m := keyValue.FindStringSubmatch(text); len(m) > 0 {
+			key := normalizeKey(m[1])
+			if table != "" {
+				key = table + "." + key
+			}
+			locator.entries = append(locator.entries, keyEntry{line: line, key: key, index: index})
+		}
+	}
+	return locator
+}
+
+// find returns the line of the key in the index-th array table, a negative
+// index means the first key not reported yet
+func (l *keyLocator) find(key string, index int) int {
+	key = normalizeKey(key)
+	for _, entry := range l.entries {
+		if entry.key != key || (index >= 0 && entry.index != index) || (index < 0 && l.used[entry.line]) {
+			continue
+		}
+		l.used[entry.line] = true
+		return entry.line
+	}
+
+	// the key may be absent, e.g. a missing field, so point to its table
+	if idx := strings.LastIndex(key, "."); idx > 0 {
+		return l.find(key[:idx], index)
+	}
+	return 0
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer(`"`, "", "'", "", " ", "", "\t", "").Replace(key)
+}
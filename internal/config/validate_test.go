@@ -0,0 +1,142 @@
+package config
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "valid config",
+			content: `
+skip_paths = [ ".git" ]
+extra_skip_paths = [ "vendor" ]
+
+[[detectors]]
+name = "typescript"
+glob = "*.ts"
+run = "tsx {path}"
+`,
+		},
+		{
+			name:    "syntax error",
+			content: `skip_paths = [ ".git"`,
+			want:    []string{`:1: (last key "skip_paths"): expected a comma`},
+		},
+		{
+			name:    "wrong type",
+			content: "\nskip_paths = \".git\"",
+			want:    []string{`:2: (last key "skip_paths"): incompatible types`},
+		},
+		{
+			name: "wrong type among other problems",
+			content: `
+max_depth = "deep"
+include_paths = [ "cmd/[a-" ]
+skpi = 1
+
+[[detectors]]
+name = "zig"
+glob = "*.zig"
+`,
+			want: []string{
+				`:2: (last key "max_depth"): incompatible types`,
+				`:3: include_paths: bad glob "cmd/[a-"`,
+				`:4: unknown key "skpi"`,
+				`:6: detector "zig": neither run nor build command`,
+			},
+		},
+		{
+			name: "unknown keys",
+			content: `
+skip_pahts = [ ".git" ]
+
+[commands.".cpp"]
+rnu = "g++"
+`,
+			want: []string{
+				`:2: unknown key "skip_pahts"`,
+				`:5: unknown key "commands.\".cpp\".rnu"`,
+			},
+		},
+		{
+			name: "invalid path patterns",
+			content: `
+skip_paths = [ ".git" ]
+include_paths = [ "cmd/[a-" ]
+`,
+			want: []string{`:3: include_paths: bad glob "cmd/[a-"`},
+		},
+		{
+			name: "invalid values",
+			content: `
+auto_pick_margin = 20
+container_engine = "lxc"
+max_depth = -1
+`,
+			want: []string{
+				`:3: container_engine: unknown engine "lxc", expected one of docker, podman`,
+				`:4: max_depth: must not be negative`,
+			},
+		},
+		{
+			name: "invalid detectors",
+			content: `
+[[detectors]]
+name = "typescript"
+glob = "*.ts"
+run = "tsx {path}"
+
+[[detectors]]
+name = "zig"
+glob = "[*.zig"
+content = "main("
+`,
+			want: []string{
+				`:7: detector "zig": neither run nor build command`,
+				`:9: detector "zig": bad glob "[*.zig": syntax error in pattern`,
+				`:10: detector "zig": bad content regexp`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile, err := ioutil.TempFile(os.TempDir(), "test")
+			require.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+
+			require.NoError(t, ioutil.WriteFile(tmpFile.Name(), []byte(tt.content), fs.ModePerm))
+
+			err = Validate(tmpFile.Name())
+			if len(tt.want) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			require.Len(t, errs, len(tt.want))
+			for idx, want := range tt.want {
+				assert.True(t,
+					strings.HasPrefix(errs[idx].Error(), tmpFile.Name()+want),
+					"%q has no prefix %q", errs[idx].Error(), want,
+				)
+			}
+
+			setConfigEnv(t, tmpFile.Name(), t.TempDir())
+			_, err = GetConfig()
+			assert.ErrorAs(t, err, &errs)
+		})
+	}
+}
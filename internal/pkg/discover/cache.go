@@ -0,0 +1,172 @@
+package discover
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+// cacheVersion invalidates caches written by detectors of other versions
+const cacheVersion = 1
+
+// lockManifests are project files that are not ranked as manifests, but
+// change what detectors find next to them
+// nolint: gochecknoglobals
+var lockManifests = []string{
+	"deno.jsonc", "bun.lockb", "bun.lock", "bunfig.toml", "uv.lock",
+	"settings.gradle", "settings.gradle.kts", "cabal.project", "Makefile.am",
+}
+
+type (
+	// discoveryCache keeps detection results of a search root between runs.
+	// A directory is rescanned with its whole subtree if its mtime or the
+	// mtime of a manifest in it changed, as files of the subtree may be
+	// detected differently then. Files of unchanged directories are read
+	// again only if they changed themselves.
+	discoveryCache struct {
+		Version int                   `json:"version"`
+		Config  string                `json:"config"`
+		Dirs    map[string]cachedDir  `json:"dirs"`
+		Files   map[string]cachedFile `json:"files"`
+	}
+
+	cachedDir struct {
+		ModTime   time.Time            `json:"mod_time"`
+		Manifests map[string]time.Time `json:"manifests,omitempty"`
+	}
+
+	// cachedFile is the detection result of a file, files without entry
+	// points are cached too
+	cachedFile struct {
+		ModTime   time.Time   `json:"mod_time"`
+		Size      int64       `json:"size"`
+		Mode      fs.FileMode `json:"mode"`
+		Extension Extension   `json:"extension,omitempty"`
+		Paths     []Filename  `json:"paths,omitempty"`
+	}
+)
+
+func cacheDir() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "auto-launcher")
+}
+
+// cachePath returns the cache file of the search root
+func cachePath(root string) string {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(cacheDir(), hex.EncodeToString(sum[:8])+".json")
+}
+
+// ClearCache removes discovery caches of all projects
+func ClearCache() error {
+	return os.RemoveAll(cacheDir())
+}
+
+// loadCache reads the cache of the search root, it is empty if there is no
+// cache yet or it was written for another config
+func loadCache(root string, cfg config.Config) *discoveryCache {
+	fresh := &discoveryCache{
+		Version: cacheVersion,
+		Config:  configHash(cfg),
+		Dirs:    make(map[string]cachedDir),
+		Files:   make(map[string]cachedFile),
+	}
+
+	content, err := ioutil.ReadFile(cachePath(root))
+	if err != nil {
+		return fresh
+	}
+
+	var cache discoveryCache
+	if json.Unmarshal(content, &cache) != nil || cache.Version != fresh.Version || cache.Config != fresh.Config {
+		return fresh
+	}
+	if cache.Dirs == nil || cache.Files == nil {
+		return fresh
+	}
+	return &cache
+}
+
+func (c *discoveryCache) save(root string) error {
+	content, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	path := cachePath(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // nolint: gomnd
+		return err
+	}
+
+	// concurrent launches must not see a half written cache
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0o644); err != nil { // nolint: gomnd
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// changed reports whether the directory differs from its cached state
+func (c *discoveryCache) changed(path string, info fs.FileInfo) bool {
+	dir, ok := c.Dirs[path]
+	if !ok || !dir.ModTime.Equal(info.ModTime()) {
+		return true
+	}
+
+	for name, modTime := range dir.Manifests {
+		manifest, err := os.Stat(filepath.Join(path, name))
+		if err != nil || !manifest.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the cached result of an unchanged file
+func (c *discoveryCache) lookup(path string, info fs.FileInfo) (cachedFile, bool) {
+	file, ok := c.Files[path]
+	if !ok || !file.ModTime.Equal(info.ModTime()) || file.Size != info.Size() || file.Mode != info.Mode() {
+		return cachedFile{}, false
+	}
+	return file, true
+}
+
+// configHash identifies the config, results of other configs are not reused
+func configHash(cfg config.Config) string {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// isManifest reports whether detection of other files depends on the file
+func isManifest(name string) bool {
+	for _, list := range [][]string{manifests, lockManifests} {
+		for _, manifest := range list {
+			if name == manifest {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package discover
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	writeTree(t, map[string]string{
+		"run.sh":        "echo",
+		"app/main.py":   "if __name__ == '__main__':\n    pass\n",
+		"app/lib.py":    "import os",
+		"tools/gen.py":  "if __name__ == '__main__':\n    pass\n",
+		"tools/main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	// pin mtimes, so that changes below are seen whatever the clock
+	// resolution of the file system is
+	past := time.Now().Add(-time.Hour)
+	for _, p := range []string{"app/main.py", "app/lib.py", "tools/gen.py", "tools/main.go", "app", "tools", "."} {
+		require.NoError(t, os.Chtimes(p, past, past))
+	}
+
+	discover := func() map[Extension][]Filename {
+		executables, err := discoverExecutables(config.Config{}, Options{}, walkOptions{})
+		require.NoError(t, err)
+		return executables
+	}
+
+	want := map[Extension][]Filename{
+		PythonExtension: {"app/main.py", "tools/gen.py"},
+		BashExtension:   {"run.sh"},
+		GoExtension:     {"tools/main.go"},
+	}
+	assert.EqualValues(t, want, discover())
+
+	cache := loadCache(".", config.Config{})
+	require.Contains(t, cache.Files, "app/lib.py")
+	assert.Empty(t, cache.Files["app/lib.py"].Extension)
+
+	// results of unchanged files are not detected again, so a forged
+	// result shows that the cache is used
+	forged := cache.Files["tools/gen.py"]
+	forged.Extension = BashExtension
+	forged.Paths = []Filename{"tools/gen.py"}
+	cache.Files["tools/gen.py"] = forged
+	require.NoError(t, cache.save("."))
+	assert.EqualValues(t, map[Extension][]Filename{
+		PythonExtension: {"app/main.py"},
+		BashExtension:   {"run.sh", "tools/gen.py"},
+		GoExtension:     {"tools/main.go"},
+	}, discover())
+
+	// a new manifest changes the directory, so it is scanned again
+	require.NoError(t, ioutil.WriteFile("tools/go.mod", []byte("module tools\n"), fs.ModePerm))
+	assert.EqualValues(t, want, discover())
+
+	// a changed file is detected again in an unchanged directory
+	require.NoError(t, ioutil.WriteFile("app/lib.py", []byte("if __name__ == '__main__':\n    pass\n"), fs.ModePerm))
+	want[PythonExtension] = []Filename{"app/lib.py", "app/main.py", "tools/gen.py"}
+	assert.EqualValues(t, want, discover())
+
+	// other configs do not reuse results
+	assert.Empty(t, loadCache(".", config.Config{MaxDepth: 1}).Files)
+
+	executables, err := discoverExecutables(config.Config{}, Options{NoCache: true}, walkOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, want, executables)
+
+	require.NoError(t, ClearCache())
+	assert.Empty(t, loadCache(".", config.Config{}).Files)
+}
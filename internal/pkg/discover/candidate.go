@@ -0,0 +1,95 @@
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+var (
+	ErrNoExecutables   = errors.New("no executables found")
+	ErrNoSuchCandidate = errors.New("no such candidate")
+	ErrAmbiguousChoice = errors.New("several executables found and no terminal to choose from")
+)
+
+// Candidate is a discovered executable with its launch command
+type Candidate struct {
+	Extension Extension `json:"extension"`
+	Path      Filename  `json:"path"`
+	Command   string    `json:"command"`
+	// Description tells what the entry point does if its file documents it
+	Description string `json:"description,omitempty"`
+	// Score estimates how likely the candidate is the entry point
+	Score int `json:"score"`
+	// Number picks the candidate by --pick, it follows the ranking without
+	// past choices, so it is the same on every machine and every run
+	Number int `json:"number"`
+}
+
+// ListCandidates returns all discovered executables from the most likely
+// entry point to the least likely one, the pick of opts is ignored
+func ListCandidates(cfg config.Config, opts Options) ([]Candidate, error) {
+	executables, err := discoverExecutables(cfg, opts, walkOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if executables, err = onlyRunExecutables(executables, cfg); err != nil {
+		return nil, err
+	}
+
+	project := projectDir()
+	candidates := rankCandidates(project, executables, loadHistory())
+	describeCandidates(cfg, candidates)
+	for idx := range candidates {
+		if candidates[idx].Command, err = prepareCommand(cfg, candidates[idx].Extension, candidates[idx].Path); err != nil {
+			return nil, err
+		}
+	}
+	return candidates, nil
+}
+
+// describeCandidates fills descriptions from detectors that know them
+func describeCandidates(cfg config.Config, candidates []Candidate) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return
+	}
+
+	for idx := range candidates {
+		if d, ok := reg.lookup(candidates[idx].Extension); ok {
+			candidates[idx].Description = describe(d, candidates[idx].Path)
+		}
+	}
+}
+
+// pickCandidate finds a candidate by its number or by its path
+func pickCandidate(candidates []Candidate, pick string) (Candidate, error) {
+	if number, err := strconv.Atoi(pick); err == nil {
+		for _, c := range candidates {
+			if c.Number == number {
+				return c, nil
+			}
+		}
+		return Candidate{}, fmt.Errorf("%w: %d is out of range 1..%d", ErrNoSuchCandidate, number, len(candidates))
+	}
+
+	for _, c := range candidates {
+		if filepath.Clean(c.Path) == filepath.Clean(pick) {
+			return c, nil
+		}
+	}
+	return Candidate{}, fmt.Errorf("%w: %s", ErrNoSuchCandidate, pick)
+}
+
+// ambiguousError lists candidates for the user to choose with --pick
+func ambiguousError(candidates []Candidate) error {
+	var sb strings.Builder
+	for _, c := range candidates {
+		fmt.Fprintf(&sb, "\n  %d. %s", c.Number, c.Path)
+	}
+	return fmt.Errorf("%w, choose one with --pick <number|path>:%s", ErrAmbiguousChoice, sb.String())
+}
@@ -0,0 +1,93 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCandidates(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	writeTree(t, map[string]string{
+		"main.go":           "package main\n\nfunc main() {}\n",
+		"scripts/run.sh":    "echo",
+		"scripts/deploy.sh": "echo",
+		Makefile:            "all:\n",
+		"api.proto":         "syntax = \"proto3\";",
+	})
+
+	// files of detectors without a run command are not candidates
+	cfg := config.Config{Detectors: []config.Detector{
+		{Name: "proto", Glob: "*.proto", Commands: config.Commands{Build: "protoc {path}"}},
+	}}
+	got, err := ListCandidates(cfg, Options{})
+	require.NoError(t, err)
+	assert.EqualValues(t, []Candidate{
+		{Extension: ".go", Path: "main.go", Command: "go run main.go $*", Score: 120, Number: 1},
+		{Extension: Makefile, Path: Makefile + ":all", Command: "make all $*", Score: 100, Number: 2},
+		{Extension: ".sh", Path: "scripts/deploy.sh", Command: "bash scripts/deploy.sh $*", Score: 90, Number: 3},
+		{Extension: ".sh", Path: "scripts/run.sh", Command: "bash scripts/run.sh $*", Score: 90, Number: 4},
+	}, got)
+}
+
+func TestPickCandidate(t *testing.T) {
+	candidates := []Candidate{
+		{Extension: ".sh", Path: "scripts/run.sh", Number: 2},
+		{Extension: ".go", Path: "cmd/server/main.go", Number: 1},
+	}
+
+	tests := []struct {
+		name string
+		pick string
+		want Candidate
+		err  error
+	}{
+		{
+			name: "by number",
+			pick: "2",
+			want: candidates[0],
+		},
+		{
+			name: "by path",
+			pick: "./cmd/server/main.go",
+			want: candidates[1],
+		},
+		{
+			name: "index out of range",
+			pick: "3",
+			err:  ErrNoSuchCandidate,
+		},
+		{
+			name: "unknown path",
+			pick: "main.go",
+			err:  ErrNoSuchCandidate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickCandidate(candidates, tt.pick)
+			require.ErrorIs(t, err, tt.err)
+			assert.EqualValues(t, tt.want, got)
+		})
+	}
+}
+
+func TestChooseCommandWithoutTerminal(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	writeTree(t, map[string]string{"build.sh": "echo", "run.sh": "echo"})
+
+	_, err := ChooseCommand(config.Config{}, Options{})
+	require.ErrorIs(t, err, ErrAmbiguousChoice)
+	assert.Contains(t, err.Error(), "1. build.sh")
+	assert.Contains(t, err.Error(), "2. run.sh")
+
+	command, err := ChooseCommand(config.Config{}, Options{Pick: "run.sh"})
+	require.NoError(t, err)
+	assert.Equal(t, "bash run.sh $*", command)
+}
@@ -0,0 +1,212 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	CMakeExecutableDecl = regexp.MustCompile(`(?im)^\s*add_executable\s*\(\s*([^\s()]+)(\s+(IMPORTED|ALIAS)\b)?`)
+	MesonExecutableDecl = regexp.MustCompile(`(?m)\bexecutable\s*\(\s*['"]([\w.+-]+)['"]`)
+	AutomakeProgramDecl = regexp.MustCompile(`(?m)^\s*s?bin_PROGRAMS\s*\+?=(.*)$`)
+)
+
+// buildSystemDetector offers executables declared in build files of C and
+// C++ build systems. The project root is the topmost directory of nested
+// build files, or the directory of rootFile if the build system has one.
+type buildSystemDetector struct {
+	file     Filename
+	rootFile Filename
+	targets  func(content string) []string
+	// build returns a script building the target in the project root and
+	// the path of the executable relative to the root
+	build func(dir, target string, release bool) (string, string)
+}
+
+// nolint: gochecknoglobals
+var (
+	cmakeDetector = buildSystemDetector{
+		file: "CMakeLists.txt",
+		targets: func(content string) []string {
+			var targets []string
+			for _, m := range CMakeExecutableDecl.FindAllStringSubmatch(content, -1) {
+				if m[3] == "" {
+					targets = append(targets, m[1])
+				}
+			}
+			return targets
+		},
+		build: func(dir, target string, release bool) (string, string) {
+			configure := "cmake -S . -B build"
+			if release {
+				configure += " -DCMAKE_BUILD_TYPE=Release"
+			}
+			return fmt.Sprintf("%s && cmake --build build --target %s", configure, target),
+				filepath.Join("build", dir, target)
+		},
+	}
+
+	mesonDetector = buildSystemDetector{
+		file: "meson.build",
+		targets: func(content string) []string {
+			var targets []string
+			for _, m := range MesonExecutableDecl.FindAllStringSubmatch(content, -1) {
+				targets = append(targets, m[1])
+			}
+			return targets
+		},
+		build: func(dir, target string, release bool) (string, string) {
+			setup := "([ -d builddir ] || meson setup builddir)"
+			if release {
+				// setup refuses an existing build directory
+				setup = "(if [ -d builddir ]; then meson configure -Dbuildtype=release builddir; " +
+					"else meson setup --buildtype=release builddir; fi)"
+			}
+			return fmt.Sprintf("%s && meson compile -C builddir %s", setup, target),
+				filepath.Join("builddir", dir, target)
+		},
+	}
+
+	autotoolsDetector = buildSystemDetector{
+		file:     "Makefile.am",
+		rootFile: "configure.ac",
+		targets: func(content string) []string {
+			content = strings.ReplaceAll(content, "\\\n", " ")
+			var targets []string
+			for _, m := range AutomakeProgramDecl.FindAllStringSubmatch(content, -1) {
+				targets = append(targets, strings.Fields(m[1])...)
+			}
+			return targets
+		},
+		build: func(dir, target string, release bool) (string, string) {
+			configure := "([ -f Makefile ] || ./configure)"
+			if release {
+				configure = "./configure"
+			}
+			return fmt.Sprintf("([ -x configure ] || autoreconf -i) && %s && make", configure),
+				filepath.Join(dir, target)
+		},
+	}
+
+	// buildSystemDetectors build executables in place of single sources
+	buildSystemDetectors = []buildSystemDetector{cmakeDetector, mesonDetector, autotoolsDetector}
+)
+
+func (d buildSystemDetector) Name() Extension { return d.file }
+
+func (d buildSystemDetector) Match(path string, info fs.FileInfo) bool {
+	return info.Name() == d.file && len(d.Targets(path)) > 0
+}
+
+// Targets are executables in the order they are declared
+func (d buildSystemDetector) Targets(path string) []string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, target := range d.targets(string(content)) {
+		// names made of variables are known to the build system only
+		if !seen[target] && !strings.Contains(target, "$") {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func (d buildSystemDetector) RunCommand(path string) (string, error) {
+	root, build, executable, err := d.plan(path, false)
+	if err != nil {
+		return "", err
+	}
+	return inDir(root, fmt.Sprintf("%s && ./%s %s", build, executable, BashArgs)), nil
+}
+
+func (d buildSystemDetector) BuildCommand(path, name string) ([]string, error) {
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	root, build, executable, err := d.plan(path, true)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"bash", "-c", inDir(root, fmt.Sprintf("%s && cp %s %s", build, executable, output))}, nil
+}
+
+// plan returns the project root, the script building the target in it and
+// the path of the executable relative to the root
+func (d buildSystemDetector) plan(path string, release bool) (string, string, string, error) {
+	path, target := splitTarget(path)
+	if target == "" {
+		return "", "", "", fmt.Errorf("%w: no target in %s", ErrCommandNotFound, path)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	root := d.projectRoot(dir)
+	sub, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", "", "", err
+	}
+	if root, err = relativeToWd(root); err != nil {
+		return "", "", "", err
+	}
+
+	build, executable := d.build(sub, shellQuote(target), release)
+	return root, build, executable, nil
+}
+
+// projectRoot returns the absolute directory the build system runs in
+func (d buildSystemDetector) projectRoot(dir string) string {
+	if d.rootFile != "" {
+		if root, ok := findUp(dir, d.rootFile); ok {
+			return root
+		}
+		return dir
+	}
+
+	root := dir
+	for parent := filepath.Dir(root); parent != root; parent = filepath.Dir(root) {
+		if _, err := os.Stat(filepath.Join(parent, d.file)); err != nil {
+			break
+		}
+		root = parent
+	}
+	return root
+}
+
+// inDir runs the script in dir relative to the working directory
+func inDir(dir, script string) string {
+	if dir == "." {
+		return script
+	}
+	return fmt.Sprintf("cd %s && %s", shellQuote(dir), script)
+}
+
+// builtBySystem reports whether sources in dir belong to a project whose
+// build files declare executables, they are offered instead of single
+// sources. Libraries built by the project keep their sources with a main.
+func builtBySystem(dir string) bool {
+	_, ok := walkUpInRepo(dir, func(dir string) bool {
+		for _, d := range buildSystemDetectors {
+			if len(d.Targets(filepath.Join(dir, d.file))) > 0 {
+				return true
+			}
+		}
+		return false
+	})
+	return ok
+}
@@ -0,0 +1,111 @@
+package discover
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSystems(t *testing.T) {
+	writeTree(t, map[string]string{
+		"cmake/CMakeLists.txt": `
+project(demo CXX)
+add_subdirectory(tools)
+add_executable(app main.cpp)
+`,
+		"cmake/tools/CMakeLists.txt": `
+add_executable(gen gen.cpp)
+add_executable(${PROJECT_NAME}_cli cli.cpp)
+add_executable(demo::gen ALIAS gen)
+`,
+		"cmake/main.cpp":         "int main() {}",
+		"meson/meson.build":      "project('demo', 'c')\nexecutable('demo', 'main.c')\n",
+		"meson/main.c":           "int main() {}",
+		"autotools/configure.ac": "AC_INIT([hello], [1.0])",
+		"autotools/src/Makefile.am": `
+bin_PROGRAMS = hello \
+	world
+noinst_PROGRAMS = bench
+`,
+		"autotools/src/hello.c":  "int main() {}",
+		"plain/main.c":           "int main() {}",
+		"library/CMakeLists.txt": "add_library(util util.c)\n",
+		"library/demo.c":         "int main() {}",
+	})
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		"Makefile.am":    {"autotools/src/Makefile.am:hello", "autotools/src/Makefile.am:world"},
+		"CMakeLists.txt": {"cmake/CMakeLists.txt:app", "cmake/tools/CMakeLists.txt:gen"},
+		"meson.build":    {"meson/meson.build:demo"},
+		CExtension:       {"library/demo.c", "plain/main.c"},
+	}, got)
+
+	output, err := filepath.Abs("app")
+	require.NoError(t, err)
+
+	tests := []struct {
+		path      string
+		ext       string
+		wantRun   string
+		wantBuild string
+	}{
+		{
+			path:      "cmake/tools/CMakeLists.txt:gen",
+			ext:       "CMakeLists.txt",
+			wantRun:   "cd cmake && cmake -S . -B build && cmake --build build --target gen && ./build/tools/gen $*",
+			wantBuild: "cd cmake && cmake -S . -B build -DCMAKE_BUILD_TYPE=Release && cmake --build build --target gen && cp build/tools/gen " + output,
+		},
+		{
+			path:      "meson/meson.build:demo",
+			ext:       "meson.build",
+			wantRun:   "cd meson && ([ -d builddir ] || meson setup builddir) && meson compile -C builddir demo && ./builddir/demo $*",
+			wantBuild: "cd meson && (if [ -d builddir ]; then meson configure -Dbuildtype=release builddir; else meson setup --buildtype=release builddir; fi) && meson compile -C builddir demo && cp builddir/demo " + output,
+		},
+		{
+			path:      "autotools/src/Makefile.am:world",
+			ext:       "Makefile.am",
+			wantRun:   "cd autotools && ([ -x configure ] || autoreconf -i) && ([ -f Makefile ] || ./configure) && make && ./src/world $*",
+			wantBuild: "cd autotools && ([ -x configure ] || autoreconf -i) && ./configure && make && cp src/world " + output,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			run, err := prepareCommand(config.Config{}, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+
+			build, err := prepareBuildCommand(config.Config{}, tt.ext, tt.path, "app")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bash", "-c", tt.wantBuild}, build)
+		})
+	}
+}
+
+func TestBuiltBySystem(t *testing.T) {
+	writeTree(t, map[string]string{
+		"CMakeLists.txt":          "add_executable(outer main.c)\n",
+		"repo/.git/HEAD":          "ref: refs/heads/master\n",
+		"repo/main.c":             "int main() {}",
+		"repo/app/meson.build":    "executable('app', 'main.c')\n",
+		"repo/app/src/main.c":     "int main() {}",
+		"repo/lib/CMakeLists.txt": "add_library(util util.c)\n",
+		"repo/lib/demo/main.c":    "int main() {}",
+		"repo/tools/Makefile.am":  "bin_PROGRAMS = tool\n",
+		"repo/tools/tool/main.c":  "int main() {}",
+	})
+
+	for dir, want := range map[string]bool{
+		"repo":          false,
+		"repo/app/src":  true,
+		"repo/lib/demo": false,
+		"repo/tools":    true,
+		".":             true,
+	} {
+		assert.Equal(t, want, builtBySystem(dir), dir)
+	}
+}
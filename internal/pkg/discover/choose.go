@@ -3,206 +3,264 @@ package discover
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/ant1k9/auto-launcher/internal/config"
-	ui "github.com/gizak/termui/v3"
-	"github.com/gizak/termui/v3/widgets"
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
 )
 
-var (
-	listedPathsPattern = regexp.MustCompile(`\d+\. (.*)`)
+var ErrCommandNotFound = errors.New("command not found")
 
-	ErrCommandNotFound = errors.New("command not found")
-)
+func prepareCommand(cfg config.Config, ext, path string) (string, error) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return "", err
+	}
 
-// nolint: maintidx
-func prepareCommand(ext, path string) (string, error) {
-	switch ext {
-	case CExtension:
-		cFiles := filepath.Join(filepath.Dir(path), "*.c")
-		return "gcc -O2 -o main " + cFiles + " && ./main " + BashArgs, nil
-	case CPPExtension:
-		cppFiles := filepath.Join(filepath.Dir(path), "*.cpp")
-		return "g++ -O2 -std=c++17 -o main " + cppFiles + " && ./main " + BashArgs, nil
-	case RustExtension:
-		return "cargo run " + BashArgs, nil
-	case PythonExtension:
-		return fmt.Sprintf("python %s %s", path, BashArgs), nil
-	case JavaScriptExtension:
-		return fmt.Sprintf("node %s %s", path, BashArgs), nil
-	case GoExtension:
-		return fmt.Sprintf("go run %s %s", path, BashArgs), nil
-	case BashExtension:
-		return fmt.Sprintf("bash %s %s", path, BashArgs), nil
-	case FishExtension:
-		return fmt.Sprintf("fish %s %s", path, BashArgs), nil
-	case Makefile, MakeExtension:
-		return "make " + BashArgs, nil
-	case Dockerfile:
-		return prepareDockerCommand()
-	default:
+	d, ok := reg.lookup(ext)
+	if !ok {
 		return "", ErrCommandNotFound
 	}
+	return d.RunCommand(path)
 }
 
-func prepareBuildCommand(ext, path, name string) ([]string, error) {
-	switch ext {
-	case RustExtension:
-		return prepareRustBuildCommand(path)
-	case GoExtension:
-		return prepareGoBuildCommand(path, name)
-	case Makefile, MakeExtension:
-		return []string{"make", "install"}, nil
-	default:
+func prepareBuildCommand(cfg config.Config, ext, path, name string) ([]string, error) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := reg.lookup(ext)
+	if !ok {
 		return nil, ErrCommandNotFound
 	}
+	return d.BuildCommand(path, name)
 }
 
-func prepareGoBuildCommand(path, name string) ([]string, error) {
-	dir, _ := filepath.Split(path)
-	switch {
-	case len(dir) > 1:
-		dir = "./" + filepath.Join(dir)
-	default:
-		dir = "."
-	}
-	return []string{"go", "build", "-o", name, dir}, nil
+type Options struct {
+	// Pick selects a candidate by its 1-based index in the list order or by
+	// its path without asking the user
+	Pick string
+	// NoCache walks the whole tree without the discovery cache and does not
+	// update it
+	NoCache bool
+	// Verbose prints every path the discovery skipped, otherwise only the
+	// number of paths that could not be read is printed
+	Verbose bool
+	// NoHistory does not remember explicit choices, for projects that are
+	// not launched again such as temporary clones
+	NoHistory bool
 }
 
-func prepareRustBuildCommand(path string) ([]string, error) {
-	pathParts := strings.Split(path, string(os.PathSeparator))
-	for idx := range pathParts {
-		if pathParts[idx] == "src" {
-			return []string{"cargo", "install", "--path", filepath.Join(pathParts[:idx]...)}, nil
-		}
+// ChooseExecutable discovers an executable and saves its launch command to
+// the RunFile, nothing is saved if the choice was cancelled
+func ChooseExecutable(cfg config.Config, opts Options) error {
+	command, err := ChooseCommand(cfg, opts)
+	if err != nil || command == "" {
+		return err
 	}
-	return []string{"cargo", "install", "--path", "."}, nil
+
+	return ioutil.WriteFile(RunFile, []byte(command), fs.ModePerm)
 }
 
-func prepareDockerCommand() (string, error) {
-	dir, err := os.Getwd()
+// ChooseCommand discovers an executable and returns its launch command, the
+// command is empty if the choice was cancelled
+func ChooseCommand(cfg config.Config, opts Options) (string, error) {
+	stop, found := habitualChoice(cfg, opts, false)
+	executables, err := discoverExecutables(cfg, opts, walkOptions{stop: stop})
 	if err != nil {
 		return "", err
 	}
+	if executables, err = onlyRunExecutables(executables, cfg); err != nil {
+		return "", err
+	}
 
-	baseDir := filepath.Base(dir)
-	return fmt.Sprintf(
-		"docker build -t %[1]s:local .\ndocker run --rm -ti %[2]s %[1]s:local",
-		baseDir, BashArgs,
-	), nil
+	candidates := habitual(rankCandidates(projectDir(), executables, loadHistory()), found)
+	describeCandidates(cfg, candidates)
+	return choose(candidates, opts, cfg, func(ext, path string) (string, error) {
+		return prepareCommand(cfg, ext, path)
+	})
 }
 
-func saveExecutable(ext, path string) error {
-	command, err := prepareCommand(ext, path)
+func ChooseBuildCommand(name string, cfg config.Config, opts Options) ([]string, error) {
+	stop, found := habitualChoice(cfg, opts, true)
+	executables, err := discoverExecutables(cfg, opts, walkOptions{stop: stop})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if executables, err = onlyBuildExecutables(executables, cfg); err != nil {
+		return nil, err
 	}
 
-	return ioutil.WriteFile(RunFile, []byte(command), fs.ModePerm)
+	candidates := habitual(rankCandidates(projectDir(), executables, loadHistory()), found)
+	describeCandidates(cfg, candidates)
+	return choose(candidates, opts, cfg, func(ext, path string) ([]string, error) {
+		return prepareBuildCommand(cfg, ext, path, name)
+	})
 }
 
-func ChooseExecutable(cfg config.Config) error {
-	executables, err := getExecutables(".", cfg)
-	if err != nil {
-		return err
+// discoverExecutables walks the working directory, files that have not
+// changed since the last discovery are not read again unless opts.NoCache
+func discoverExecutables(cfg config.Config, opts Options, walk walkOptions) (map[Extension][]Filename, error) {
+	if !opts.NoCache {
+		walk.cache = loadCache(".", cfg)
 	}
 
-	if len(executables) == 1 {
-		for ext, paths := range executables {
-			if len(paths) == 1 {
-				return saveExecutable(ext, paths[0])
-			}
+	walk.report = &walkReport{}
+
+	executables, err := walkExecutables(".", cfg, walk)
+	if err == nil && walk.cache != nil {
+		// the cache only speeds discovery up, so it is fine to lose it
+		_ = walk.cache.save(".")
+	}
+	printReport(os.Stderr, walk.report, opts.Verbose)
+	return executables, err
+}
+
+// printReport warns about paths that could not be read, verbose also lists
+// paths skipped on purpose
+func printReport(w io.Writer, report *walkReport, verbose bool) {
+	if verbose {
+		for _, s := range report.skipped {
+			fmt.Fprintln(w, s)
 		}
+		return
 	}
 
-	_, err = chooseExecutableInteractively(executables)
-	return err
+	if n := report.unreadable(); n > 0 {
+		fmt.Fprintf(w, "warning: %d path(s) could not be read, run with --verbose to list them\n", n)
+	}
 }
 
-func chooseExecutableInteractively(executables map[Extension][]Filename) (any, error) {
-	return chooseInteractively(executables, func(ext, path string) (any, error) {
-		return nil, saveExecutable(ext, path)
-	})
+// habit stops discovery at the entry point the user keeps choosing in the
+// project, found is set once it does
+type habit struct {
+	found Filename
 }
 
-func ChooseBuildCommand(name string, cfg config.Config) ([]string, error) {
-	executables, err := getBuildExecutables(".", cfg)
-	if err != nil {
-		return nil, err
+// habitualChoice stops discovery at the single most chosen candidate of the
+// project if its score leads any other candidate could get by the margin,
+// so the rest of the tree cannot change the pick. Discovery is complete if
+// the user picks by the flag or auto-picking is disabled.
+func habitualChoice(cfg config.Config, opts Options, build bool) (func(Extension, Filename) bool, *habit) {
+	found := &habit{}
+	reg, err := newRegistry(cfg)
+	if err != nil || opts.Pick != "" || cfg.AutoPickMargin <= 0 {
+		return nil, found
 	}
 
-	if len(executables) == 1 {
-		for ext, paths := range executables {
-			if len(paths) == 1 {
-				return prepareBuildCommand(ext, paths[0], name)
-			}
-		}
+	project, h := projectDir(), loadHistory()
+	favourite, rival := h.favourite(project)
+	if favourite == "" || h.count(project, favourite) < maxHistoryChoices {
+		return nil, found
+	}
+	if rival > maxHistoryChoices {
+		rival = maxHistoryChoices
 	}
+	rivalScore := maxBaseScore + historyBonus*rival
 
-	return chooseBuilderInteractively(executables, name)
+	return func(ext Extension, path Filename) bool {
+		if filepath.Clean(path) != favourite {
+			return false
+		}
+		if scoreCandidate(project, Candidate{Extension: ext, Path: path}, h)-rivalScore < cfg.AutoPickMargin {
+			return false
+		}
+		d, ok := reg.lookup(ext)
+		if !ok || (build && !canBuild(d, path)) || (!build && !canRun(d, path)) {
+			return false
+		}
+		found.found = path
+		return true
+	}, found
 }
 
-func chooseBuilderInteractively(executables map[Extension][]Filename, name string) ([]string, error) {
-	return chooseInteractively(executables, func(ext, path string) ([]string, error) {
-		return prepareBuildCommand(ext, path, name)
-	})
+// habitual leaves only the candidate the walk stopped at, the list is
+// partial, so the user is not asked to choose from it
+func habitual(candidates []Candidate, found *habit) []Candidate {
+	if found.found == "" {
+		return candidates
+	}
+	for _, c := range candidates {
+		if c.Path == found.found {
+			return []Candidate{c}
+		}
+	}
+	return candidates
 }
 
-// nolint: maintidx
-func chooseInteractively[T any](
-	executables map[Extension][]Filename,
+// choose asks the user only if there is more than one candidate, no pick,
+// no clear winner by the margin and both stdin and stdout are terminals.
+// The picker of the config asks instead of the built-in chooser if it is
+// set. Choices made by the user are remembered to rank candidates next time,
+// picks by the flag are not, as they may come from scripts.
+func choose[T any](
+	candidates []Candidate,
+	opts Options,
+	cfg config.Config,
 	resultFn func(ext, path string) (T, error),
 ) (result T, err error) {
-	if err = ui.Init(); err != nil {
-		return result, fmt.Errorf("failed to initialize termui: %w", err)
-	}
-	defer ui.Close()
-
-	extensions, widgetsList := prepareWidgetsList(executables)
-	ui.Render(widgetsList)
-	uiEvents := ui.PollEvents()
-
-	for {
-		e := <-uiEvents
-		switch e.ID {
-		case "q", "<C-c>":
-			return result, nil
-		case "<Enter>":
-			if m := listedPathsPattern.FindStringSubmatch(widgetsList.Rows[widgetsList.SelectedRow]); len(m) > 0 {
-				return resultFn(extensions[widgetsList.SelectedRow], m[1])
+	var (
+		c        Candidate
+		explicit bool
+	)
+
+	switch {
+	case opts.Pick != "":
+		if c, err = pickCandidate(candidates, opts.Pick); err != nil {
+			return result, err
+		}
+	case len(candidates) == 0:
+		return result, ErrNoExecutables
+	case len(candidates) == 1, clearWinner(candidates, cfg.AutoPickMargin):
+		c = candidates[0]
+	case !utils.IsInteractive():
+		return result, ambiguousError(candidates)
+	case cfg.Picker != "":
+		var ok bool
+		if c, ok, err = chooseExternally(candidates, cfg.Picker); err != nil || !ok {
+			return result, err
+		}
+		explicit = true
+	default:
+		var ok bool
+		command := func(c Candidate) string {
+			result, err := resultFn(c.Extension, c.Path)
+			if err != nil {
+				return err.Error()
 			}
-			return result, fmt.Errorf(
-				"unexpected row data: %s", widgetsList.Rows[widgetsList.SelectedRow],
-			)
-		case "j", "<Down>":
-			widgetsList.ScrollDown()
-		case "k", "<Up>":
-			widgetsList.ScrollUp()
+			return commandText(result)
 		}
-		ui.Render(widgetsList)
+		if c, ok, err = chooseInteractively(candidates, command); err != nil || !ok {
+			return result, err
+		}
+		explicit = true
 	}
-}
 
-func prepareWidgetsList(executables map[Extension][]Filename) ([]string, *widgets.List) {
-	extensions := make([]string, 0, len(executables))
+	if explicit && len(candidates) > 1 && !opts.NoHistory {
+		// the history only improves ranking, so it is fine to lose it
+		_ = loadHistory().record(projectDir(), c.Path)
+	}
+	return resultFn(c.Extension, c.Path)
+}
 
-	widgetsList := widgets.NewList()
-	widgetsList.Title = "Choose executable to run further:\n"
-	for ext, paths := range executables {
-		for _, path := range paths {
-			widgetsList.Rows = append(widgetsList.Rows, fmt.Sprintf("%d. %s", len(widgetsList.Rows)+1, path))
-			extensions = append(extensions, ext)
+// commandText shows a run or build command of any kind to the user, build
+// commands are run by bash
+func commandText(command any) string {
+	switch command := command.(type) {
+	case string:
+		return command
+	case []string:
+		if len(command) == 3 && command[0] == "bash" && command[1] == "-c" {
+			return command[2]
 		}
+		return strings.Join(command, " ")
+	default:
+		return fmt.Sprint(command)
 	}
-
-	widgetsList.SetRect(0, 0, 50, 10) // nolint: gomnd
-	widgetsList.SelectedRowStyle = ui.NewStyle(ui.ColorGreen)
-
-	return extensions, widgetsList
 }
@@ -29,7 +29,7 @@ func TestPrepareCommand(t *testing.T) {
 			name: "go command",
 			ext:  ".go",
 			path: "main.go",
-			want: "go run main.go $*",
+			want: "go run . $*",
 		},
 		{
 			name: "rust command",
@@ -72,7 +72,7 @@ func TestPrepareCommand(t *testing.T) {
 			ext:  Dockerfile,
 			path: Dockerfile,
 			want: fmt.Sprintf(
-				"docker build -t %[1]s:local .\ndocker run --rm -ti $* %[1]s:local",
+				"docker build -t %[1]s:local .\ndocker run --rm -i $([ -t 0 ] && echo -t) $* %[1]s:local",
 				baseDir,
 			),
 		},
@@ -97,7 +97,7 @@ func TestPrepareCommand(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := prepareCommand(tt.ext, tt.path)
+			got, err := prepareCommand(config.Config{}, tt.ext, tt.path)
 			if got != tt.want {
 				t.Errorf("prepareCommand() = %v, want %v", got, tt.want)
 			}
@@ -162,7 +162,7 @@ func TestPrepareBuildCommand(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := prepareBuildCommand(tt.ext, tt.path, tt.executable)
+			got, err := prepareBuildCommand(config.Config{}, tt.ext, tt.path, tt.executable)
 			assert.EqualValues(t, tt.want, got)
 
 			if tt.err != err {
@@ -173,6 +173,8 @@ func TestPrepareBuildCommand(t *testing.T) {
 }
 
 func TestChooseExecutable(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	tests := []struct {
 		name        string
 		genFilename string
@@ -205,9 +207,12 @@ func main()	{}
 				fs.ModePerm,
 			))
 
+			wd, err := os.Getwd()
+			require.NoError(t, err)
 			require.NoError(t, os.Chdir(rootPath))
+			defer os.Chdir(wd) // nolint: errcheck
 
-			err = ChooseExecutable(config.Config{})
+			err = ChooseExecutable(config.Config{}, Options{})
 			require.NoError(t, err)
 
 			content, err := ioutil.ReadFile(".run")
@@ -218,6 +223,8 @@ func main()	{}
 }
 
 func TestChooseBuildCommand(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	tests := []struct {
 		name        string
 		genFilename string
@@ -252,12 +259,79 @@ func main()	{}
 				fs.ModePerm,
 			))
 
+			wd, err := os.Getwd()
+			require.NoError(t, err)
 			require.NoError(t, os.Chdir(rootPath))
+			defer os.Chdir(wd) // nolint: errcheck
 
-			buildCommand, err := ChooseBuildCommand(tt.executable, config.Config{})
+			buildCommand, err := ChooseBuildCommand(tt.executable, config.Config{}, Options{})
 			require.NoError(t, err)
 
 			assert.EqualValues(t, tt.want, buildCommand)
 		})
 	}
 }
+
+func TestHabitualChoice(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	writeTree(t, map[string]string{
+		"go.mod":            "module demo",
+		"main.go":           "package main\n\nfunc main() {}\n",
+		"scripts/deploy.sh": "echo",
+	})
+
+	tests := []struct {
+		name     string
+		choices  map[Filename]int
+		opts     Options
+		wantStop Filename
+	}{
+		{
+			name:     "the only habit at the top",
+			choices:  map[Filename]int{"main.go": 3},
+			wantStop: "main.go",
+		},
+		{
+			name:    "another habit may outscore it",
+			choices: map[Filename]int{"main.go": 10, "scripts/deploy.sh": 3},
+		},
+		{
+			name:    "a tie",
+			choices: map[Filename]int{"main.go": 3, "scripts/deploy.sh": 3},
+		},
+		{
+			name:    "an unlikely file may be outscored by a new one",
+			choices: map[Filename]int{"scripts/deploy.sh": 5},
+		},
+		{
+			name:    "picked by the flag",
+			choices: map[Filename]int{"main.go": 3},
+			opts:    Options{Pick: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.RemoveAll(historyPath()))
+			h := loadHistory()
+			for path, count := range tt.choices {
+				for i := 0; i < count; i++ {
+					require.NoError(t, h.record(projectDir(), path))
+				}
+			}
+
+			stop, found := habitualChoice(config.Config{AutoPickMargin: 50}, tt.opts, false)
+			executables, err := walkExecutables(".", config.Config{}, walkOptions{stop: stop})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStop, found.found)
+
+			candidates := habitual(rankCandidates(projectDir(), executables, loadHistory()), found)
+			if tt.wantStop != "" {
+				require.Len(t, candidates, 1)
+				assert.Equal(t, tt.wantStop, candidates[0].Path)
+			} else {
+				assert.Len(t, candidates, 2)
+			}
+		})
+	}
+}
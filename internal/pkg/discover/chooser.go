@@ -0,0 +1,377 @@
+package discover
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+const (
+	// previewSize is the prefix of a file shown in the preview pane
+	previewSize = 16 << 10
+	// sidePreviewWidth is the narrowest terminal with the preview pane
+	sidePreviewWidth = 80
+	promptHeight     = 3
+
+	chooserHelp = "type to filter  ↑/↓ move  PgUp/PgDn page  Home/End top/bottom  Enter choose  Esc quit"
+)
+
+type (
+	// chooser is the state of the interactive choice: the filter, the rows
+	// grouped by language and the selected one. It knows nothing of termui.
+	chooser struct {
+		candidates []Candidate
+		query      string
+		rows       []chooserRow
+		// selected is an index of rows, it never points to a header
+		selected int
+		matched  int
+	}
+
+	// chooserRow is a candidate or the header of a language group
+	chooserRow struct {
+		// candidate is an index of candidates, -1 for headers
+		candidate int
+		text      string
+	}
+
+	chooserAction int
+)
+
+const (
+	chooserKeep chooserAction = iota
+	chooserQuit
+	chooserPick
+)
+
+func newChooser(candidates []Candidate) *chooser {
+	c := &chooser{candidates: candidates}
+	c.filter()
+	return c
+}
+
+// filter keeps candidates matching the query, the best matches first. They
+// are grouped by language in the order of the best candidate of each group.
+func (c *chooser) filter() {
+	type match struct{ idx, score int }
+	var matches []match
+	for idx, candidate := range c.candidates {
+		if score, ok := fuzzyScore(c.query, candidate.Path+" "+candidate.Description); ok {
+			matches = append(matches, match{idx: idx, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	var (
+		groups  []Extension
+		grouped = make(map[Extension][]int)
+	)
+	for _, m := range matches {
+		ext := c.candidates[m.idx].Extension
+		if _, ok := grouped[ext]; !ok {
+			groups = append(groups, ext)
+		}
+		grouped[ext] = append(grouped[ext], m.idx)
+	}
+
+	c.rows, c.matched = c.rows[:0], len(matches)
+	for _, ext := range groups {
+		if len(groups) > 1 {
+			c.rows = append(c.rows, chooserRow{candidate: -1, text: fmt.Sprintf("[%s](fg:cyan,mod:bold)", ext)})
+		}
+		for _, idx := range grouped[ext] {
+			c.rows = append(c.rows, chooserRow{candidate: idx, text: candidateRow(c.candidates[idx])})
+		}
+	}
+
+	c.selected = 0
+	c.move(0)
+}
+
+// candidateRow starts with the number of the candidate, which --pick
+// understands
+func candidateRow(c Candidate) string {
+	row := fmt.Sprintf("%d. %s", c.Number, c.Path)
+	if c.Description != "" {
+		row += "  # " + c.Description
+	}
+	return row
+}
+
+// move selects the candidate delta rows away, headers are stepped over
+func (c *chooser) move(delta int) {
+	if len(c.rows) == 0 {
+		return
+	}
+
+	idx, step := c.selected+delta, 1
+	if delta < 0 {
+		step = -1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(c.rows) {
+		idx = len(c.rows) - 1
+	}
+
+	// a group is never empty, so a candidate follows every header
+	for c.rows[idx].candidate < 0 {
+		if idx+step < 0 || idx+step >= len(c.rows) {
+			step = -step
+		}
+		idx += step
+	}
+	c.selected = idx
+}
+
+// current is the selected candidate, false if nothing matches the query
+func (c *chooser) current() (Candidate, int, bool) {
+	if len(c.rows) == 0 {
+		return Candidate{}, -1, false
+	}
+	idx := c.rows[c.selected].candidate
+	return c.candidates[idx], idx, true
+}
+
+// handle applies a termui key, page is the number of visible rows. Printable
+// keys are typed into the query, so moving is left to the arrows and Escape
+// clears the query or quits if it is empty.
+func (c *chooser) handle(key string, page int) chooserAction {
+	switch key {
+	case "<C-c>":
+		return chooserQuit
+	case "<Enter>":
+		if _, _, ok := c.current(); ok {
+			return chooserPick
+		}
+	case "<Down>", "<C-n>":
+		c.move(1)
+	case "<Up>", "<C-p>":
+		c.move(-1)
+	case "<PageDown>":
+		c.move(page)
+	case "<PageUp>":
+		c.move(-page)
+	case "<Home>":
+		c.move(-len(c.rows))
+	case "<End>":
+		c.move(len(c.rows))
+	default:
+		return c.edit(key)
+	}
+	return chooserKeep
+}
+
+func (c *chooser) edit(key string) chooserAction {
+	switch key {
+	case "<Escape>":
+		if c.query == "" {
+			return chooserQuit
+		}
+		c.query = ""
+	case "<Backspace>", "<C-<Backspace>>":
+		if c.query == "" {
+			return chooserKeep
+		}
+		_, size := utf8.DecodeLastRuneInString(c.query)
+		c.query = c.query[:len(c.query)-size]
+	case "<C-u>":
+		c.query = ""
+	case "<Space>":
+		c.query += " "
+	default:
+		if utf8.RuneCountInString(key) != 1 {
+			return chooserKeep
+		}
+		c.query += key
+	}
+	c.filter()
+	return chooserKeep
+}
+
+func (c *chooser) prompt() string {
+	if c.query == "" {
+		return chooserHelp
+	}
+	return fmt.Sprintf("> %s_  (Esc clears)", c.query)
+}
+
+// fuzzyScore matches every word of the pattern as a case-insensitive
+// subsequence of the text. Consecutive letters and letters at the start of
+// path elements and words score higher.
+func fuzzyScore(pattern, text string) (int, bool) {
+	total, runes, lower := 0, []rune(text), []rune(strings.ToLower(text))
+	for _, word := range strings.Fields(strings.ToLower(pattern)) {
+		best, found, w := 0, false, []rune(word)
+		for start := range lower {
+			if lower[start] != w[0] {
+				continue
+			}
+			if score, ok := matchWord(w, runes, lower, start); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// matchWord greedily matches the word from the start position of the text
+func matchWord(word, runes, lower []rune, start int) (int, bool) {
+	score, prev, pos := 0, -2, start
+	for _, r := range word {
+		for pos < len(lower) && lower[pos] != r {
+			pos++
+		}
+		if pos == len(lower) {
+			return 0, false
+		}
+
+		score++
+		if pos == prev+1 {
+			score += 2
+		}
+		if pos == 0 || isWordBoundary(runes[pos-1]) || (unicode.IsUpper(runes[pos]) && unicode.IsLower(runes[pos-1])) {
+			score += 3
+		}
+		prev, pos = pos, pos+1
+	}
+	return score, true
+}
+
+func isWordBoundary(r rune) bool {
+	return strings.ContainsRune("/\\_-.: ", r)
+}
+
+// previewFile returns the first lines of the file of the entry point, it
+// is empty for directories and unreadable files
+func previewFile(path Filename, lines int) string {
+	file, _ := splitTarget(path)
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	head, err := ioutil.ReadAll(io.LimitReader(f, previewSize))
+	if err != nil {
+		return ""
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return "(binary file)"
+	}
+
+	rows := strings.Split(strings.ReplaceAll(string(head), "\t", "    "), "\n")
+	if len(rows) > lines {
+		rows = rows[:lines]
+	}
+	return strings.Join(rows, "\n")
+}
+
+// chooserView lays the chooser out on the terminal: candidates on the left,
+// the preview on the right if there is room and the prompt at the bottom
+type chooserView struct {
+	list        *widgets.List
+	preview     *widgets.Paragraph
+	prompt      *widgets.Paragraph
+	showPreview bool
+}
+
+func newChooserView() *chooserView {
+	v := &chooserView{list: widgets.NewList(), preview: widgets.NewParagraph(), prompt: widgets.NewParagraph()}
+	v.list.Title = "Choose executable to run further"
+	v.list.SelectedRowStyle = ui.NewStyle(ui.ColorGreen)
+	v.preview.Title = "Preview"
+	v.preview.WrapText = false
+	return v
+}
+
+func (v *chooserView) layout(width, height int) {
+	listHeight := height - promptHeight
+	v.showPreview = width >= sidePreviewWidth
+	if v.showPreview {
+		v.list.SetRect(0, 0, width/2, listHeight)
+		v.preview.SetRect(width/2, 0, width, listHeight)
+	} else {
+		v.list.SetRect(0, 0, width, listHeight)
+	}
+	v.prompt.SetRect(0, listHeight, width, height)
+}
+
+func (v *chooserView) render(c *chooser, preview func(idx int) string) {
+	v.list.Rows = v.list.Rows[:0]
+	for _, row := range c.rows {
+		v.list.Rows = append(v.list.Rows, row.text)
+	}
+	v.list.SelectedRow = c.selected
+
+	v.prompt.Title = fmt.Sprintf("%d/%d", c.matched, len(c.candidates))
+	v.prompt.Text = c.prompt()
+
+	if !v.showPreview {
+		ui.Render(v.list, v.prompt)
+		return
+	}
+
+	v.preview.Text = ""
+	if _, idx, ok := c.current(); ok {
+		v.preview.Text = preview(idx)
+	}
+	ui.Render(v.list, v.preview, v.prompt)
+}
+
+// chooseInteractively returns false if the user quits without a choice,
+// command gives the launch command of a candidate for the preview
+func chooseInteractively(candidates []Candidate, command func(Candidate) string) (Candidate, bool, error) {
+	if err := ui.Init(); err != nil {
+		return Candidate{}, false, fmt.Errorf("failed to initialize termui: %w", err)
+	}
+	defer ui.Close()
+
+	c, v := newChooser(candidates), newChooserView()
+	v.layout(ui.TerminalDimensions())
+
+	previews := make(map[int]string)
+	preview := func(idx int) string {
+		if _, ok := previews[idx]; !ok {
+			previews[idx] = command(candidates[idx]) + "\n\n" + previewFile(candidates[idx].Path, v.preview.Inner.Dy())
+		}
+		return previews[idx]
+	}
+
+	v.render(c, preview)
+	for e := range ui.PollEvents() {
+		switch e.Type {
+		case ui.ResizeEvent:
+			size, _ := e.Payload.(ui.Resize)
+			v.layout(size.Width, size.Height)
+			previews = make(map[int]string)
+			ui.Clear()
+		case ui.KeyboardEvent:
+			switch c.handle(e.ID, v.list.Inner.Dy()) {
+			case chooserQuit:
+				return Candidate{}, false, nil
+			case chooserPick:
+				candidate, _, _ := c.current()
+				return candidate, true, nil
+			}
+		default:
+			continue
+		}
+		v.render(c, preview)
+	}
+	return Candidate{}, false, nil
+}
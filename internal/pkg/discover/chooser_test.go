@@ -0,0 +1,122 @@
+package discover
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		wantMatch bool
+	}{
+		{pattern: "", text: "main.go", wantMatch: true},
+		{pattern: "srv", text: "cmd/server/main.go", wantMatch: true},
+		{pattern: "SRV", text: "cmd/server/main.go", wantMatch: true},
+		{pattern: "cmd main", text: "cmd/server/main.go", wantMatch: true},
+		{pattern: "vrs", text: "cmd/server/main.go", wantMatch: false},
+		{pattern: "cmd tool", text: "cmd/server/main.go", wantMatch: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" in "+tt.text, func(t *testing.T) {
+			_, ok := fuzzyScore(tt.pattern, tt.text)
+			assert.Equal(t, tt.wantMatch, ok)
+		})
+	}
+
+	boundary, _ := fuzzyScore("ms", "cmd/migrate/seed.go")
+	inside, _ := fuzzyScore("ms", "cmd/items.go")
+	assert.Greater(t, boundary, inside)
+
+	consecutive, _ := fuzzyScore("serve", "serve.sh")
+	scattered, _ := fuzzyScore("serve", "scripts/review.sh")
+	assert.Greater(t, consecutive, scattered)
+}
+
+func TestChooser(t *testing.T) {
+	candidates := []Candidate{
+		{Extension: GoExtension, Path: "cmd/server/main.go", Number: 1},
+		{Extension: BashExtension, Path: "scripts/deploy.sh", Description: "ship it", Number: 2},
+		{Extension: GoExtension, Path: "cmd/migrate/main.go", Number: 3},
+		{Extension: BashExtension, Path: "scripts/seed.sh", Number: 4},
+	}
+	rows := func(c *chooser) []int {
+		var got []int
+		for _, row := range c.rows {
+			got = append(got, row.candidate)
+		}
+		return got
+	}
+	selected := func(c *chooser) Filename {
+		candidate, _, _ := c.current()
+		return candidate.Path
+	}
+
+	c := newChooser(candidates)
+	assert.Equal(t, []int{-1, 0, 2, -1, 1, 3}, rows(c))
+	assert.Equal(t, Filename("cmd/server/main.go"), selected(c))
+
+	for _, step := range []struct {
+		key  string
+		want Filename
+	}{
+		{key: "<Down>", want: "cmd/migrate/main.go"},
+		{key: "<End>", want: "scripts/seed.sh"},
+		{key: "<Up>", want: "scripts/deploy.sh"},
+		{key: "<Home>", want: "cmd/server/main.go"},
+	} {
+		assert.Equal(t, chooserKeep, c.handle(step.key, 10))
+		assert.Equal(t, step.want, selected(c), step.key)
+	}
+
+	c.handle("<C-n>", 10)
+	c.handle("<Down>", 10)
+	assert.Equal(t, Filename("scripts/deploy.sh"), selected(c), "headers are stepped over")
+	c.handle("<PageUp>", 10)
+	assert.Equal(t, Filename("cmd/server/main.go"), selected(c))
+
+	for _, key := range []string{"s", "h", "i", "p"} {
+		assert.Equal(t, chooserKeep, c.handle(key, 10))
+	}
+	assert.Equal(t, []int{1}, rows(c), "descriptions are searched too")
+	assert.Equal(t, "> ship_  (Esc clears)", c.prompt())
+
+	c.handle("<C-u>", 10)
+	c.handle("s", 10)
+	c.handle("e", 10)
+	assert.Equal(t, []int{-1, 0, -1, 3, 1}, rows(c), "better matches come first")
+	c.handle("e", 10)
+	c.handle("d", 10)
+	assert.Equal(t, []int{3}, rows(c), "one group has no header")
+	assert.Equal(t, Filename("scripts/seed.sh"), selected(c))
+
+	assert.Equal(t, chooserPick, c.handle("<Enter>", 10))
+
+	assert.Equal(t, chooserKeep, c.handle("<Escape>", 10))
+	assert.Equal(t, chooserHelp, c.prompt(), "the filter is cleared")
+	assert.Len(t, rows(c), 6)
+	assert.Equal(t, chooserQuit, c.handle("<Escape>", 10))
+	assert.Equal(t, chooserQuit, c.handle("<C-c>", 10))
+
+	c.handle("g", 10)
+	assert.ElementsMatch(t, []int{0, 2}, rows(c), "letters are typed, not commands")
+	c.handle("<Backspace>", 10)
+	c.handle("x", 10)
+	assert.Empty(t, rows(c))
+	assert.Equal(t, chooserKeep, c.handle("<Enter>", 10), "nothing to pick")
+}
+
+func TestPreviewFile(t *testing.T) {
+	rootPath := writeTree(t, map[string]string{
+		"Makefile": "all:\n\tgo build\n\ntest:\n\tgo test\n",
+		"app":      "\x7fELF\x00",
+	})
+
+	assert.Equal(t, "all:\n    go build", previewFile(path.Join(rootPath, "Makefile")+":test", 2))
+	assert.Equal(t, "(binary file)", previewFile(path.Join(rootPath, "app"), 2))
+	assert.Empty(t, previewFile(path.Join(rootPath, "missing.sh"), 2))
+	assert.Empty(t, previewFile(rootPath, 2))
+}
@@ -5,15 +5,37 @@ const (
 
 	BashArgs = "$*"
 
-	CExtension          = ".c"
-	CPPExtension        = ".cpp"
-	RustExtension       = ".rs"
-	PythonExtension     = ".py"
-	JavaScriptExtension = ".js"
-	GoExtension         = ".go"
-	BashExtension       = ".sh"
-	FishExtension       = ".fish"
-	MakeExtension       = ".mk"
-	Makefile            = "Makefile"
-	Dockerfile          = "Dockerfile"
+	// TargetSeparator joins a file and one of its targets in a candidate
+	// path, e.g. package.json:build
+	TargetSeparator = ":"
+
+	CExtension            = ".c"
+	CPPExtension          = ".cpp"
+	RustExtension         = ".rs"
+	PythonExtension       = ".py"
+	JavaScriptExtension   = ".js"
+	TypeScriptExtension   = ".ts"
+	TSModuleExtension     = ".mts"
+	GoExtension           = ".go"
+	BashExtension         = ".sh"
+	FishExtension         = ".fish"
+	MakeExtension         = ".mk"
+	Makefile              = "Makefile"
+	Dockerfile            = "Dockerfile"
+	PackageJSON           = "package.json"
+	PyProject             = "pyproject.toml"
+	JavaExtension         = ".java"
+	KotlinExtension       = ".kt"
+	PomXML                = "pom.xml"
+	RubyExtension         = ".rb"
+	PerlExtension         = ".pl"
+	LuaExtension          = ".lua"
+	PHPExtension          = ".php"
+	ElixirExtension       = ".ex"
+	ElixirScriptExtension = ".exs"
+	HaskellExtension      = ".hs"
+	ZigExtension          = ".zig"
+	NimExtension          = ".nim"
+	MixFile               = "mix.exs"
+	BuildZig              = "build.zig"
 )
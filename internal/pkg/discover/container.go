@@ -0,0 +1,254 @@
+package discover
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultEngine = "docker"
+
+var (
+	DockerStageDecl = regexp.MustCompile(`(?i)^FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?`)
+	DockerArgDecl   = regexp.MustCompile(`(?i)^ARG\s+([A-Za-z_]\w*)\s*$`)
+	DockerFromFlag  = regexp.MustCompile(`(?i)--from=(\S+)`)
+	ProcfileDecl    = regexp.MustCompile(`^([\w-]+):\s*(.+)$`)
+)
+
+type (
+	// engineDetector is a detector of files run by a container engine,
+	// the engine is chosen by the config
+	engineDetector interface {
+		withEngine(engine string) Detector
+	}
+
+	// dockerDetector builds an image of the Dockerfile and runs it, leaf
+	// stages of multi-stage builds are offered as separate targets
+	dockerDetector struct {
+		runOnly
+		engine string
+	}
+
+	// composeDetector starts every service of a compose file separately
+	composeDetector struct {
+		runOnly
+		engine string
+	}
+
+	// procfileDetector runs process types of Procfiles by their commands
+	procfileDetector struct{ runOnly }
+
+	// dockerfile is what the launcher needs to know about a Dockerfile
+	dockerfile struct {
+		stages []string // names of stages, empty for unnamed ones
+		used   map[string]bool
+		args   []string // build arguments without default values
+	}
+
+	// procfileProcess is a process type of a Procfile
+	procfileProcess struct {
+		name    string
+		command string
+	}
+)
+
+// nolint: gochecknoglobals
+var composeFiles = map[Filename]bool{
+	"compose.yaml": true, "compose.yml": true, "docker-compose.yaml": true, "docker-compose.yml": true,
+}
+
+func (d dockerDetector) withEngine(engine string) Detector {
+	d.engine = engine
+	return d
+}
+
+func (dockerDetector) Name() Extension { return Dockerfile }
+
+func (dockerDetector) Match(_ string, info fs.FileInfo) bool {
+	name := info.Name()
+	switch {
+	case name == Dockerfile, name == "Containerfile", strings.HasSuffix(name, "."+Dockerfile):
+		return true
+	default:
+		return strings.HasPrefix(name, Dockerfile+".") && filepath.Ext(name) != ".dockerignore"
+	}
+}
+
+// Targets are the default stage and named stages no other stage is built
+// from, e.g. a dev stage next to the release one
+func (dockerDetector) Targets(path string) []string {
+	file := parseDockerfile(path)
+
+	targets := []string{""}
+	for idx, stage := range file.stages {
+		if stage != "" && idx != len(file.stages)-1 && !file.used[strings.ToLower(stage)] {
+			targets = append(targets, stage)
+		}
+	}
+	return targets
+}
+
+// RunCommand tags the image by the working directory, a tty is allocated
+// only if the launcher has one
+func (d dockerDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	image := strings.ToLower(filepath.Base(dir))
+	build := []string{containerEngine(d.engine), "build"}
+	if target != "" {
+		image += "-" + strings.ToLower(target)
+		build = append(build, "--target", shellQuote(target))
+	}
+	image += ":local"
+
+	for _, arg := range parseDockerfile(path).args {
+		build = append(build, "--build-arg", arg)
+	}
+	build = append(build, "-t", image)
+	if filepath.Base(path) != Dockerfile {
+		build = append(build, "-f", shellQuote(path))
+	}
+	build = append(build, shellQuote(filepath.Dir(path)))
+
+	return fmt.Sprintf(
+		"%s\n%s run --rm -i $([ -t 0 ] && echo -t) %s %s",
+		strings.Join(build, " "), containerEngine(d.engine), BashArgs, image,
+	), nil
+}
+
+// parseDockerfile finds stages and build arguments the user may pass from
+// the environment
+func parseDockerfile(path string) dockerfile {
+	file := dockerfile{used: make(map[string]bool)}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return file
+	}
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := DockerStageDecl.FindStringSubmatch(line); len(m) > 0 {
+			file.used[strings.ToLower(m[1])] = true
+			file.stages = append(file.stages, m[2])
+		}
+		for _, m := range DockerFromFlag.FindAllStringSubmatch(line, -1) {
+			file.used[strings.ToLower(m[1])] = true
+		}
+		if m := DockerArgDecl.FindStringSubmatch(line); len(m) > 0 && !seen[m[1]] {
+			seen[m[1]] = true
+			file.args = append(file.args, m[1])
+		}
+	}
+	return file
+}
+
+func (d composeDetector) withEngine(engine string) Detector {
+	d.engine = engine
+	return d
+}
+
+func (composeDetector) Name() Extension { return "compose.yaml" }
+
+func (d composeDetector) Match(path string, info fs.FileInfo) bool {
+	return composeFiles[info.Name()] && len(d.Targets(path)) > 0
+}
+
+// Targets are services in the order they are declared
+func (composeDetector) Targets(path string) []string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var compose struct {
+		Services yaml.Node `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return nil
+	}
+
+	var services []string
+	nodes := compose.Services.Content
+	for idx := 0; idx+1 < len(nodes); idx += 2 {
+		services = append(services, nodes[idx].Value)
+	}
+	return services
+}
+
+// RunCommand lets compose find the file, so override files are merged too
+func (d composeDetector) RunCommand(path string) (string, error) {
+	path, service := splitTarget(path)
+	return inDir(filepath.Dir(path), fmt.Sprintf(
+		"%s compose up %s %s", containerEngine(d.engine), BashArgs, shellQuote(service),
+	)), nil
+}
+
+func (procfileDetector) Name() Extension { return "Procfile" }
+
+func (d procfileDetector) Match(path string, info fs.FileInfo) bool {
+	name := info.Name()
+	if name != "Procfile" && !strings.HasPrefix(name, "Procfile.") {
+		return false
+	}
+	return len(d.Targets(path)) > 0
+}
+
+func (procfileDetector) Targets(path string) []string {
+	processes := parseProcfile(path)
+	names := make([]string, 0, len(processes))
+	for _, p := range processes {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// RunCommand runs the process command itself, so no process manager is
+// needed to launch a single process
+func (procfileDetector) RunCommand(path string) (string, error) {
+	path, name := splitTarget(path)
+	for _, p := range parseProcfile(path) {
+		if p.name == name {
+			return inDir(filepath.Dir(path), p.command+" "+BashArgs), nil
+		}
+	}
+	return "", ErrCommandNotFound
+}
+
+func parseProcfile(path string) []procfileProcess {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var processes []procfileProcess
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if m := ProcfileDecl.FindStringSubmatch(scanner.Text()); len(m) > 0 {
+			processes = append(processes, procfileProcess{name: m[1], command: strings.TrimSpace(m[2])})
+		}
+	}
+	return processes
+}
+
+func containerEngine(engine string) string {
+	if engine == "" {
+		return defaultEngine
+	}
+	return engine
+}
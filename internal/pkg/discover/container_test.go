@@ -0,0 +1,97 @@
+package discover
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainers(t *testing.T) {
+	rootPath := writeTree(t, map[string]string{
+		Dockerfile: `
+ARG GO_VERSION=1.19
+FROM golang:${GO_VERSION} AS build
+ARG GITHUB_TOKEN
+COPY . .
+RUN go build -o /app .
+
+FROM build AS test
+RUN go test ./...
+
+FROM golang:${GO_VERSION} AS dev
+CMD ["go", "run", "."]
+
+FROM alpine AS release
+COPY --from=build /app /app
+ENTRYPOINT ["/app"]
+`,
+		"docker/worker.Dockerfile":  "FROM alpine\n",
+		"docker/.dockerignore":      "*.md\n",
+		"Dockerfile.dockerignore":   "*.md\n",
+		"compose.yaml":              "services:\n  web:\n    build: .\n  db:\n    image: postgres\n",
+		"deploy/docker-compose.yml": "version: '3'\n",
+		"Procfile": `
+# processes of the app
+web: bundle exec puma -p $PORT
+worker: bundle exec sidekiq
+`,
+		"Procfile.dev": "css: bin/rails tailwindcss:watch\n",
+	})
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		Dockerfile:     {"Dockerfile", "Dockerfile:test", "Dockerfile:dev", "docker/worker.Dockerfile"},
+		"compose.yaml": {"compose.yaml:web", "compose.yaml:db"},
+		"Procfile":     {"Procfile:web", "Procfile:worker", "Procfile.dev:css"},
+	}, got)
+
+	image := filepath.Base(rootPath)
+	tests := []struct {
+		path   string
+		ext    string
+		engine string
+		want   string
+	}{
+		{
+			path: "Dockerfile",
+			ext:  Dockerfile,
+			want: "docker build --build-arg GITHUB_TOKEN -t " + image + ":local .\n" +
+				"docker run --rm -i $([ -t 0 ] && echo -t) $* " + image + ":local",
+		},
+		{
+			path:   "Dockerfile:dev",
+			ext:    Dockerfile,
+			engine: "podman",
+			want: "podman build --target dev --build-arg GITHUB_TOKEN -t " + image + "-dev:local .\n" +
+				"podman run --rm -i $([ -t 0 ] && echo -t) $* " + image + "-dev:local",
+		},
+		{
+			path: "docker/worker.Dockerfile",
+			ext:  Dockerfile,
+			want: "docker build -t " + image + ":local -f docker/worker.Dockerfile docker\n" +
+				"docker run --rm -i $([ -t 0 ] && echo -t) $* " + image + ":local",
+		},
+		{
+			path:   "compose.yaml:db",
+			ext:    "compose.yaml",
+			engine: "podman",
+			want:   "podman compose up $* db",
+		},
+		{
+			path: "Procfile:web",
+			ext:  "Procfile",
+			want: "bundle exec puma -p $PORT $*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := prepareCommand(config.Config{ContainerEngine: tt.engine}, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
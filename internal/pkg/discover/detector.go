@@ -0,0 +1,186 @@
+package discover
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+// Detector recognises entry points of one kind and knows how to launch and
+// build them
+type Detector interface {
+	// Name is the key candidates of the detector are grouped by, usually a
+	// file extension
+	Name() Extension
+	// Match reports whether the file is an entry point
+	Match(path string, info fs.FileInfo) bool
+	// RunCommand returns a bash command launching the entry point, BashArgs
+	// in it expands to the launcher arguments
+	RunCommand(path string) (string, error)
+	// BuildCommand returns a command building an executable called name,
+	// ErrCommandNotFound means that the detector cannot build
+	BuildCommand(path, name string) ([]string, error)
+}
+
+// MultiDetector is a Detector of files with several entry points, e.g.
+// scripts of package.json. Every target is a separate candidate, its path
+// is the file path and the target name joined by TargetSeparator, an
+// empty target stands for the file itself.
+type MultiDetector interface {
+	Detector
+	// Targets lists entry points of a matched file
+	Targets(path string) []string
+}
+
+// Describer is implemented by detectors that know what entry points do,
+// e.g. from comments of makefile targets
+type Describer interface {
+	Describe(path string) string
+}
+
+// registry is a list of detectors ordered by priority
+type registry []Detector
+
+// nolint: gochecknoglobals
+var detectors = registry{
+	cmakeDetector,
+	mesonDetector,
+	autotoolsDetector,
+	cDetector{extension: CExtension, compiler: "gcc -O2"},
+	cDetector{extension: CPPExtension, compiler: "g++ -O2 -std=c++17"},
+	rustDetector{},
+	goDetector{},
+	pythonDetector{},
+	pyprojectDetector{},
+	nodeDetector{},
+	denoDetector,
+	bunDetector,
+	nodeScriptDetector{extension: JavaScriptExtension, runner: "node"},
+	nodeScriptDetector{extension: TypeScriptExtension, runner: "npx tsx", skipModules: true},
+	nodeScriptDetector{extension: TSModuleExtension, runner: "npx tsx", skipModules: true},
+	scriptDetector{extension: BashExtension, interpreter: "bash"},
+	scriptDetector{extension: FishExtension, interpreter: "fish"},
+	gradleDetector{file: "build.gradle"},
+	gradleDetector{file: "build.gradle.kts"},
+	mavenDetector{},
+	javaDetector,
+	kotlinDetector,
+	rubyScriptDetector,
+	perlDetector,
+	luaDetector,
+	phpDetector,
+	elixirDetector{},
+	elixirScriptDetector{},
+	haskellDetector{},
+	zigDetector{},
+	nimDetector{},
+	makeDetector{name: Makefile},
+	makeDetector{name: MakeExtension},
+	justDetector{},
+	taskDetector{},
+	dockerDetector{},
+	composeDetector{},
+	procfileDetector{},
+	shebangDetector{},
+}
+
+// Register adds a detector in front of the built-in ones, so it is matched
+// first and replaces a built-in detector with the same name
+func Register(d Detector) {
+	detectors = append(registry{d}, detectors...)
+}
+
+// newRegistry puts detectors from the config in front of the registered ones
+// and applies command overrides to all of them
+func newRegistry(cfg config.Config) (registry, error) {
+	reg := make(registry, 0, len(cfg.Detectors)+len(detectors))
+	for _, detectorCfg := range cfg.Detectors {
+		d, err := newTemplateDetector(detectorCfg)
+		if err != nil {
+			return nil, err
+		}
+		reg = append(reg, d)
+	}
+	reg = append(reg, detectors...)
+
+	for idx, d := range reg {
+		if e, ok := d.(engineDetector); ok && cfg.ContainerEngine != "" {
+			reg[idx] = e.withEngine(cfg.ContainerEngine)
+		}
+		if commands, ok := cfg.Commands[d.Name()]; ok {
+			reg[idx] = overriddenDetector{Detector: reg[idx], commands: commands}
+		}
+	}
+	return reg, nil
+}
+
+func (reg registry) lookup(name Extension) (Detector, bool) {
+	for _, d := range reg {
+		if d.Name() == name {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+func (reg registry) match(path string, info fs.FileInfo) (Detector, bool) {
+	for _, d := range reg {
+		if d.Match(path, info) {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// candidatePaths returns the path of a matched file or paths of its targets
+func candidatePaths(d Detector, path string) []Filename {
+	md, ok := unwrap(d).(MultiDetector)
+	if !ok {
+		return []Filename{path}
+	}
+
+	targets := md.Targets(path)
+	paths := make([]Filename, 0, len(targets))
+	for _, target := range targets {
+		if target == "" {
+			paths = append(paths, path)
+			continue
+		}
+		paths = append(paths, path+TargetSeparator+target)
+	}
+	return paths
+}
+
+// describe returns the description of the entry point if the detector has it
+func describe(d Detector, path string) string {
+	if describer, ok := unwrap(d).(Describer); ok {
+		return describer.Describe(path)
+	}
+	return ""
+}
+
+// unwrap returns the built-in detector of an overridden one
+func unwrap(d Detector) Detector {
+	if o, ok := d.(overriddenDetector); ok {
+		return o.Detector
+	}
+	return d
+}
+
+func canRun(d Detector, path string) bool {
+	_, err := d.RunCommand(path)
+	return !errors.Is(err, ErrCommandNotFound)
+}
+
+func canBuild(d Detector, path string) bool {
+	_, err := d.BuildCommand(path, "")
+	return !errors.Is(err, ErrCommandNotFound)
+}
+
+// runOnly is embedded by detectors that launch entry points but cannot build
+type runOnly struct{}
+
+func (runOnly) BuildCommand(_, _ string) ([]string, error) {
+	return nil, ErrCommandNotFound
+}
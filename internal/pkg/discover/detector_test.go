@@ -0,0 +1,49 @@
+package discover
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type vDetector struct{ runOnly }
+
+func (vDetector) Name() Extension { return ".v" }
+
+func (vDetector) Match(_ string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == ".v"
+}
+
+func (vDetector) RunCommand(path string) (string, error) {
+	return "v run " + path + " " + BashArgs, nil
+}
+
+func TestRegister(t *testing.T) {
+	defer func(saved registry) { detectors = saved }(detectors)
+	Register(vDetector{})
+
+	rootPath := writeTree(t, map[string]string{"main.v": "fn main() {}", "build.sh": "fn main() {}"})
+
+	got, err := getExecutables(rootPath, config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		".v":  {path.Join(rootPath, "main.v")},
+		".sh": {path.Join(rootPath, "build.sh")},
+	}, got)
+
+	command, err := prepareCommand(config.Config{}, ".v", "main.v")
+	require.NoError(t, err)
+	assert.Equal(t, "v run main.v $*", command)
+
+	_, err = prepareBuildCommand(config.Config{}, ".v", "main.v", "executable")
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+
+	got, err = getBuildExecutables(rootPath, config.Config{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
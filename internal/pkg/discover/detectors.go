@@ -0,0 +1,44 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+type (
+	cDetector struct {
+		runOnly
+		extension Extension
+		compiler  string
+	}
+
+	// scriptDetector launches every file with the extension by interpreter,
+	// we cannot say whether it is a script or a module
+	scriptDetector struct {
+		runOnly
+		extension   Extension
+		interpreter string
+	}
+)
+
+func (d cDetector) Name() Extension { return d.extension }
+
+func (d cDetector) Match(path string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == d.extension && !builtBySystem(filepath.Dir(path)) && hasMain(path, CMainDecl)
+}
+
+func (d cDetector) RunCommand(path string) (string, error) {
+	sources := filepath.Join(filepath.Dir(path), "*"+d.extension)
+	return d.compiler + " -o main " + sources + " && ./main " + BashArgs, nil
+}
+
+func (d scriptDetector) Name() Extension { return d.extension }
+
+func (d scriptDetector) Match(_ string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == d.extension
+}
+
+func (d scriptDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf("%s %s %s", d.interpreter, path, BashArgs), nil
+}
@@ -1,19 +1,22 @@
 package discover
 
 import (
-	"io/fs"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/ant1k9/auto-launcher/internal/config"
 )
 
 var (
 	CMainDecl      = regexp.MustCompile(`(?m)(void|int)\s+main`)
-	GoMainDecl     = regexp.MustCompile(`func\s+main`)
 	PythonMainDecl = regexp.MustCompile(`if\s+__name__\s*==\s*["']__main__["']`)
 	RustMainDecl   = regexp.MustCompile(`fn\s+main`)
+
+	safeShellWord = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
 )
 
 type (
@@ -21,71 +24,153 @@ type (
 	Filename  = string
 )
 
-func isSkippedPath(path string, cfg config.Config) bool {
-	for _, skipPath := range cfg.SkipPaths {
-		if filepath.Base(path) == skipPath {
-			return true
-		}
-	}
-	return false
-}
-
+// hasMain looks for the main declaration in the first maxSniffSize bytes
 func hasMain(path string, mainDecl *regexp.Regexp) bool {
-	content, err := ioutil.ReadFile(path)
+	content, err := readPrefix(path)
 	if err != nil {
 		return false
 	}
 	return len(mainDecl.Find(content)) > 0
 }
 
-func isCExecutable(path string) bool      { return hasMain(path, CMainDecl) }
-func isGoExecutable(path string) bool     { return hasMain(path, GoMainDecl) }
-func isPythonExecutable(path string) bool { return hasMain(path, PythonMainDecl) }
-func isRustExecutable(path string) bool   { return hasMain(path, RustMainDecl) }
+// readPrefix reads up to maxSniffSize bytes of the file, entry points
+// declare themselves early enough and the rest of large files is skipped
+func readPrefix(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(io.LimitReader(file, maxSniffSize))
+}
+
+// splitTarget splits a candidate path to the file and its target, the
+// target is empty for files with a single entry point. File names may have
+// the separator too, so the path is split after the first prefix that is a
+// file, targets may have the separator as well, e.g. "package.json:bin:cli".
+// Paths that are not on disk are split at the first separator.
+func splitTarget(path Filename) (Filename, string) {
+	if isFile(path) {
+		return path, ""
+	}
 
-func getExtension(path string) string {
-	switch path {
-	case Makefile, Dockerfile:
-		return path
-	default:
-		return filepath.Ext(path)
+	first := strings.Index(path, TargetSeparator)
+	if first < 0 {
+		return path, ""
+	}
+	for idx := first; ; {
+		if isFile(path[:idx]) {
+			return path[:idx], path[idx+len(TargetSeparator):]
+		}
+		next := strings.Index(path[idx+len(TargetSeparator):], TargetSeparator)
+		if next < 0 {
+			return path[:first], path[first+len(TargetSeparator):]
+		}
+		idx += len(TargetSeparator) + next
 	}
 }
 
-func isExecutable(path string, info fs.FileInfo) bool {
-	if info.IsDir() {
-		return false
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// shellQuote quotes a word for bash if it has special characters
+func shellQuote(word string) string {
+	if safeShellWord.MatchString(word) {
+		return word
 	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
 
-	switch getExtension(info.Name()) {
-	case ".cpp", ".c":
-		return isCExecutable(path)
-	case ".rs":
-		return isRustExecutable(path)
-	case ".go":
-		return isGoExecutable(path)
-	case ".py":
-		return isPythonExecutable(path)
-	case ".fish", ".sh", ".js", ".mk", Makefile, Dockerfile:
-		return true // we cannot say is it a script or a package
-	default:
-		return false
+// relativeToWd makes an absolute path relative to the working directory
+func relativeToWd(path string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Rel(wd, path)
 }
 
-func getExecutables(root string, cfg config.Config) (map[Extension][]Filename, error) {
-	files := make(map[Extension][]Filename)
-	return files, filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
-		if isSkippedPath(filepath.Base(path), cfg) {
-			return filepath.SkipDir
+// findUp returns the nearest directory from dir upwards that contains the
+// named file
+func findUp(dir, name string) (string, bool) {
+	return findUpAny(dir, name)
+}
+
+// findUpInRepo is findUpAny that stops at the git repository root and below
+// the home directory, files above them belong to other projects
+func findUpInRepo(dir string, names ...string) (string, bool) {
+	return walkUpInRepo(dir, func(dir string) bool {
+		return containsAny(dir, names)
+	})
+}
+
+// walkUpInRepo returns the nearest directory from dir upwards up to the git
+// repository root for which found is true, the home directory is only
+// visited if dir is the home directory itself
+func walkUpInRepo(dir string, found func(dir string) bool) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	home, _ := os.UserHomeDir()
+
+	for start := dir; ; {
+		if dir == home && dir != start {
+			return "", false
+		}
+		if found(dir) {
+			return dir, true
 		}
 
-		if isExecutable(path, info) {
-			extension := getExtension(info.Name())
-			files[extension] = append(files[extension], path)
+		parent := filepath.Dir(dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || parent == dir {
+			return "", false
 		}
-		return err
-	})
+		dir = parent
+	}
+}
+
+// findUpAny returns the nearest directory from dir upwards that contains
+// one of the named files, names may be globs, e.g. "*.cabal"
+func findUpAny(dir string, names ...string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		if containsAny(dir, names) {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// containsAny reports whether dir contains one of the named files, names
+// may be globs
+func containsAny(dir string, names []string) bool {
+	for _, name := range names {
+		if strings.Contains(name, "*") {
+			if matches, _ := filepath.Glob(filepath.Join(dir, name)); len(matches) > 0 {
+				return true
+			}
+		} else if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func getExecutables(root string, cfg config.Config) (map[Extension][]Filename, error) {
+	return walkExecutables(root, cfg, walkOptions{})
 }
 
 func getBuildExecutables(root string, cfg config.Config) (map[Extension][]Filename, error) {
@@ -93,12 +178,43 @@ func getBuildExecutables(root string, cfg config.Config) (map[Extension][]Filena
 	if err != nil {
 		return nil, err
 	}
+	return onlyBuildExecutables(executables, cfg)
+}
+
+// onlyRunExecutables drops entry points without a run command, e.g. files
+// of config detectors with a build command only
+func onlyRunExecutables(executables map[Extension][]Filename, cfg config.Config) (map[Extension][]Filename, error) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyRunExecutables := make(map[Extension][]Filename)
+	for ext, filenames := range executables {
+		d, _ := reg.lookup(ext)
+		for _, filename := range filenames {
+			if canRun(d, filename) {
+				onlyRunExecutables[ext] = append(onlyRunExecutables[ext], filename)
+			}
+		}
+	}
+	return onlyRunExecutables, nil
+}
+
+// onlyBuildExecutables drops executables the detectors cannot build
+func onlyBuildExecutables(executables map[Extension][]Filename, cfg config.Config) (map[Extension][]Filename, error) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	onlyBuildExecutables := make(map[Extension][]Filename)
 	for ext, filenames := range executables {
-		switch ext {
-		case RustExtension, GoExtension, Makefile, MakeExtension:
-			onlyBuildExecutables[ext] = filenames
+		d, _ := reg.lookup(ext)
+		for _, filename := range filenames {
+			if canBuild(d, filename) {
+				onlyBuildExecutables[ext] = append(onlyBuildExecutables[ext], filename)
+			}
 		}
 	}
 	return onlyBuildExecutables, nil
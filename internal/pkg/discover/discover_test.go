@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/ant1k9/auto-launcher/internal/config"
@@ -41,6 +42,58 @@ func init()	{}
 `,
 			want: map[string][]string{},
 		},
+		{
+			name:        "go main method",
+			genFilename: "server.go",
+			genContent: `
+package main
+
+type server struct{}
+
+func (server) main() {}
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "go main out of package main",
+			genFilename: "lib.go",
+			genContent: `
+package lib
+
+// func main() is not here
+func main() {}
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "go main in a string",
+			genFilename: "gen.go",
+			genContent: `
+package main
+
+const template = "func main() {}"
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "go main excluded by build tags",
+			genFilename: "gen.go",
+			genContent: `//go:build ignore
+
+package main
+
+func main() {}
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "go main before large generated data",
+			genFilename: "gen.go",
+			genContent:  "package main\n\nfunc main() {}\n\nvar data = `" + strings.Repeat("x", maxSniffSize) + "`\n",
+			want: map[string][]string{
+				".go": {"gen.go"},
+			},
+		},
 		{
 			name:        "rust executable",
 			genFilename: "main.rs",
@@ -81,7 +134,7 @@ echo
 all:
 `,
 			want: map[string][]string{
-				Makefile: {Makefile},
+				Makefile: {Makefile + ":all"},
 			},
 		},
 		{
@@ -95,6 +148,135 @@ if __name__ == "__main__":
 				".py": {"exec.py"},
 			},
 		},
+		{
+			name:        "ruby executable",
+			genFilename: "exec.rb",
+			genContent: `
+if __FILE__ == $0
+  puts "Hello"
+end
+`,
+			want: map[string][]string{
+				".rb": {"exec.rb"},
+			},
+		},
+		{
+			name:        "ruby library",
+			genFilename: "lib.rb",
+			genContent: `
+module Lib
+end
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "perl executable",
+			genFilename: "exec.pl",
+			genContent: `
+print "Hello\n";
+`,
+			want: map[string][]string{
+				".pl": {"exec.pl"},
+			},
+		},
+		{
+			name:        "lua executable",
+			genFilename: "exec.lua",
+			genContent: `
+print("Hello")
+`,
+			want: map[string][]string{
+				".lua": {"exec.lua"},
+			},
+		},
+		{
+			name:        "lua module",
+			genFilename: "lib.lua",
+			genContent: `
+local M = {}
+return M
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "php executable",
+			genFilename: "exec.php",
+			genContent: `
+<?php
+echo $argv[1];
+`,
+			want: map[string][]string{
+				".php": {"exec.php"},
+			},
+		},
+		{
+			name:        "php template",
+			genFilename: "index.php",
+			genContent: `
+<?php echo "Hello"; ?>
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "elixir script",
+			genFilename: "exec.exs",
+			genContent: `
+IO.puts("Hello")
+`,
+			want: map[string][]string{
+				".exs": {"exec.exs"},
+			},
+		},
+		{
+			name:        "haskell executable",
+			genFilename: "Main.hs",
+			genContent: `
+main :: IO ()
+main = putStrLn "Hello"
+`,
+			want: map[string][]string{
+				".hs": {"Main.hs"},
+			},
+		},
+		{
+			name:        "haskell library",
+			genFilename: "Lib.hs",
+			genContent: `
+module Lib where
+`,
+			want: map[string][]string{},
+		},
+		{
+			name:        "zig executable",
+			genFilename: "main.zig",
+			genContent: `
+pub fn main() void {}
+`,
+			want: map[string][]string{
+				".zig": {"main.zig"},
+			},
+		},
+		{
+			name:        "nim executable",
+			genFilename: "exec.nim",
+			genContent: `
+when isMainModule:
+  echo "Hello"
+`,
+			want: map[string][]string{
+				".nim": {"exec.nim"},
+			},
+		},
+		{
+			name:        "deno executable",
+			genFilename: "server.ts",
+			genContent: `
+Deno.serve(() => new Response("Hello"));
+`,
+			want: map[string][]string{
+				"deno": {"server.ts"},
+			},
+		},
 		{
 			name:        "no executables",
 			genFilename: "file.txt",
@@ -164,11 +346,12 @@ fn main() {
 			name:        Makefile,
 			genFilename: Makefile,
 			genContent: `
-.PHONY: all
+.PHONY: all install
 all:
+install: all
 `,
 			want: map[string][]string{
-				Makefile: {Makefile},
+				Makefile: {Makefile + ":install"},
 			},
 		},
 		{
@@ -258,3 +441,48 @@ func main()	{}
 		})
 	}
 }
+
+func TestSplitTarget(t *testing.T) {
+	rootPath := t.TempDir()
+	for _, filename := range []string{"deploy:prod.sh", "package.json", "ci:jobs/Makefile"} {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(rootPath, filename)), 0755))
+		require.NoError(t, ioutil.WriteFile(path.Join(rootPath, filename), []byte("echo"), fs.ModePerm))
+	}
+
+	tests := []struct {
+		path       Filename
+		wantFile   Filename
+		wantTarget string
+	}{
+		{path: "deploy:prod.sh", wantFile: "deploy:prod.sh"},
+		{path: "package.json:bin:cli", wantFile: "package.json", wantTarget: "bin:cli"},
+		{path: "ci:jobs/Makefile:test", wantFile: "ci:jobs/Makefile", wantTarget: "test"},
+		{path: "missing/Makefile:test", wantFile: "missing/Makefile", wantTarget: "test"},
+		{path: "main.go", wantFile: "main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file, target := splitTarget(path.Join(rootPath, tt.path))
+			assert.Equal(t, path.Join(rootPath, tt.wantFile), file)
+			assert.Equal(t, tt.wantTarget, target)
+		})
+	}
+}
+
+// writeTree creates the files in a temporary directory, which is the
+// working directory until the end of the test
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	rootPath := t.TempDir()
+	for filename, content := range files {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(rootPath, filename)), 0755))
+		require.NoError(t, ioutil.WriteFile(path.Join(rootPath, filename), []byte(content), fs.ModePerm))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(rootPath))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return rootPath
+}
@@ -0,0 +1,146 @@
+package discover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const goModFile = "go.mod"
+
+// goDetector finds main packages. Only one file of a package may declare
+// func main, so every package is found once and launched as a whole.
+type goDetector struct{}
+
+func (goDetector) Name() Extension { return GoExtension }
+
+func (goDetector) Match(path string, info fs.FileInfo) bool {
+	name := info.Name()
+	if filepath.Ext(name) != GoExtension || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+
+	if ok, err := goBuildContext().MatchFile(filepath.Dir(path), name); err != nil || !ok {
+		return false
+	}
+	return hasGoMain(path)
+}
+
+func (goDetector) RunCommand(path string) (string, error) {
+	modRoot, pkg, ok := goPackage(path)
+	switch {
+	case !ok:
+		// a file out of modules is still runnable on its own
+		return fmt.Sprintf("go run %s %s", shellQuote(path), BashArgs), nil
+	case modRoot != "":
+		return inDir(modRoot, fmt.Sprintf("go run %s %s", shellQuote(pkg), BashArgs)), nil
+	default:
+		return fmt.Sprintf("go run %s %s", shellQuote(pkg), BashArgs), nil
+	}
+}
+
+func (goDetector) BuildCommand(path, name string) ([]string, error) {
+	modRoot, pkg, ok := goPackage(path)
+	if !ok || modRoot == "" {
+		return []string{"go", "build", "-o", name, pkg}, nil
+	}
+
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"bash", "-c", inDir(modRoot, fmt.Sprintf("go build -o %s %s", shellQuote(output), shellQuote(pkg)))}, nil
+}
+
+// goPackage returns the package path of the file for the go tool. If the
+// module of the file is not the one of the working directory, e.g. a nested
+// module, the package path is relative to the returned module root, which
+// the go tool has to be run from. It is false if the file is out of modules.
+func goPackage(path string) (string, string, bool) {
+	dir := filepath.Dir(path)
+
+	modRoot, ok := findUp(dir, goModFile)
+	if !ok {
+		return "", packagePath(dir), false
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", packagePath(dir), true
+	}
+
+	// the go tool resolves relative packages from the working directory
+	// as long as it is inside the same module
+	if wdRoot, ok := findUp(wd, goModFile); ok && wdRoot == modRoot {
+		return "", packagePath(dir), true
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", packagePath(dir), true
+	}
+	relRoot, err := filepath.Rel(wd, modRoot)
+	if err != nil {
+		return "", packagePath(dir), true
+	}
+	relDir, err := filepath.Rel(modRoot, absDir)
+	if err != nil {
+		return "", packagePath(dir), true
+	}
+	return relRoot, packagePath(relDir), true
+}
+
+// packagePath makes a relative directory look like a package path, the go
+// tool treats paths without a dot prefix as import paths
+func packagePath(dir string) string {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if dir == "." || filepath.IsAbs(dir) || strings.HasPrefix(dir, "../") {
+		return dir
+	}
+	return "./" + dir
+}
+
+// hasGoMain reports whether the file is in package main and declares a
+// top-level func main
+func hasGoMain(path string) bool {
+	content, err := readPrefix(path)
+	if err != nil {
+		return false
+	}
+
+	// the package clause is cheap to check before parsing the whole file
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.PackageClauseOnly)
+	if err != nil || file.Name.Name != "main" {
+		return false
+	}
+
+	// a file cut at maxSniffSize still has the declarations before the cut
+	if file, _ = parser.ParseFile(fset, path, content, parser.SkipObjectResolution); file == nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// goBuildContext is the default build context with the tags from GOFLAGS,
+// so files are matched the same way go run builds them
+func goBuildContext() *build.Context {
+	ctx := build.Default
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if flag = strings.TrimLeft(flag, "-"); strings.HasPrefix(flag, "tags=") {
+			ctx.BuildTags = append(ctx.BuildTags, strings.Split(strings.TrimPrefix(flag, "tags="), ",")...)
+		}
+	}
+	return &ctx
+}
@@ -0,0 +1,92 @@
+package discover
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		wd        string
+		path      string
+		wantRun   string
+		wantBuild []string
+	}{
+		{
+			name:      "module root",
+			files:     []string{"go.mod", "main.go"},
+			path:      "main.go",
+			wantRun:   "go run . $*",
+			wantBuild: []string{"go", "build", "-o", "app", "."},
+		},
+		{
+			name:      "command of a module",
+			files:     []string{"go.mod", "cmd/server/main.go", "cmd/server/routes.go"},
+			path:      "cmd/server/main.go",
+			wantRun:   "go run ./cmd/server $*",
+			wantBuild: []string{"go", "build", "-o", "app", "./cmd/server"},
+		},
+		{
+			name:      "subdirectory of a module",
+			files:     []string{"go.mod", "cmd/server/main.go"},
+			wd:        "cmd",
+			path:      "server/main.go",
+			wantRun:   "go run ./server $*",
+			wantBuild: []string{"go", "build", "-o", "app", "./server"},
+		},
+		{
+			name:      "nested module",
+			files:     []string{"go.mod", "tools/go.mod", "tools/cmd/gen/main.go"},
+			path:      "tools/cmd/gen/main.go",
+			wantRun:   "cd tools && go run ./cmd/gen $*",
+			wantBuild: []string{"bash", "-c", "cd tools && go build -o {root}/app ./cmd/gen"},
+		},
+		{
+			name:      "nested module with spaces",
+			files:     []string{"go.mod", "my tools/go.mod", "my tools/cmd/gen/main.go"},
+			path:      "my tools/cmd/gen/main.go",
+			wantRun:   "cd 'my tools' && go run ./cmd/gen $*",
+			wantBuild: []string{"bash", "-c", "cd 'my tools' && go build -o {root}/app ./cmd/gen"},
+		},
+		{
+			name:      "no module",
+			files:     []string{"main.go"},
+			path:      "main.go",
+			wantRun:   "go run main.go $*",
+			wantBuild: []string{"go", "build", "-o", "app", "."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for _, filename := range tt.files {
+				files[filename] = ""
+			}
+			rootPath := writeTree(t, files)
+			require.NoError(t, os.Chdir(path.Join(rootPath, tt.wd)))
+
+			got, err := goDetector{}.RunCommand(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, got)
+
+			// the temporary directory may be a symlink
+			realRoot, err := filepath.EvalSymlinks(rootPath)
+			require.NoError(t, err)
+			for idx := range tt.wantBuild {
+				tt.wantBuild[idx] = strings.ReplaceAll(tt.wantBuild[idx], "{root}", realRoot)
+			}
+
+			build, err := goDetector{}.BuildCommand(tt.path, "app")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBuild, build)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package discover
+
+import (
+	"encoding/json"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// history counts past choices of candidates per project directory
+type history map[string]map[Filename]int
+
+func historyPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "auto-launcher", "history.json")
+}
+
+// loadHistory reads the history, it is empty if there is no history yet
+func loadHistory() history {
+	h := make(history)
+	if content, err := ioutil.ReadFile(historyPath()); err == nil {
+		_ = json.Unmarshal(content, &h)
+	}
+	return h
+}
+
+func (h history) count(project string, path Filename) int {
+	return h[project][filepath.Clean(path)]
+}
+
+// favourite returns the path chosen most often in the project and how many
+// times the runner-up was chosen, the path is empty if there is a tie
+func (h history) favourite(project string) (Filename, int) {
+	var (
+		path          Filename
+		first, second int
+	)
+	for p, count := range h[project] {
+		switch {
+		case count > first:
+			path, first, second = p, count, first
+		case count > second:
+			second = count
+		}
+	}
+	if first == second {
+		return "", second
+	}
+	return path, second
+}
+
+func (h history) record(project string, path Filename) error {
+	if h[project] == nil {
+		h[project] = make(map[Filename]int)
+	}
+	h[project][filepath.Clean(path)]++
+
+	content, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	path = historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // nolint: gomnd
+		return err
+	}
+	return ioutil.WriteFile(path, content, fs.ModePerm)
+}
+
+// projectDir is the key of the current project in the history
+func projectDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,320 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	JavaMainDecl   = regexp.MustCompile(`(public\s+static|static\s+public)\s+void\s+main\s*\(`)
+	KotlinMainDecl = regexp.MustCompile(`(?m)^fun\s+main\s*\(`)
+
+	GradleApplicationDecl = regexp.MustCompile(
+		`(?m)(\bid\s*\(?\s*["']application["']|apply\s+plugin:\s*["']application["']|^\s*application\s*(\{|$))`,
+	)
+	SpringBootDecl = regexp.MustCompile(`org\.springframework\.boot|spring-boot-maven-plugin`)
+	MavenExecDecl  = regexp.MustCompile(`exec-maven-plugin`)
+	MainClassDecl  = regexp.MustCompile(`<(?:exec\.)?mainClass>\s*([\w.]+)\s*</`)
+)
+
+type (
+	// gradleDetector launches projects with the application plugin by the
+	// run task and Spring Boot projects by bootRun, the Gradle wrapper of
+	// the build is preferred
+	gradleDetector struct{ file Filename }
+
+	// mavenDetector launches projects with the Spring Boot or exec plugin
+	mavenDetector struct{}
+
+	// jvmSourceDetector launches single-file programs out of Gradle and
+	// Maven projects
+	jvmSourceDetector struct {
+		extension Extension
+		mainDecl  *regexp.Regexp
+		run       string
+		// compile makes main.jar of the file, java runs the source as is
+		// if it is empty
+		compile string
+	}
+)
+
+// nolint: gochecknoglobals
+var (
+	gradleBuildFiles    = []Filename{"build.gradle", "build.gradle.kts"}
+	gradleSettingsFiles = []Filename{"settings.gradle", "settings.gradle.kts"}
+
+	javaDetector = jvmSourceDetector{
+		extension: JavaExtension,
+		mainDecl:  JavaMainDecl,
+		run:       "java %s " + BashArgs,
+	}
+
+	kotlinDetector = jvmSourceDetector{
+		extension: KotlinExtension,
+		mainDecl:  KotlinMainDecl,
+		run:       "kotlinc %s -include-runtime -d main.jar && java -jar main.jar " + BashArgs,
+		compile:   "kotlinc %s -include-runtime -d main.jar",
+	}
+)
+
+func (d gradleDetector) Name() Extension { return d.file }
+
+func (d gradleDetector) Match(path string, info fs.FileInfo) bool {
+	if info.Name() != d.file {
+		return false
+	}
+	_, ok := gradleTask(path)
+	return ok
+}
+
+func (d gradleDetector) RunCommand(path string) (string, error) {
+	task, ok := gradleTask(path)
+	if !ok {
+		return "", fmt.Errorf("%w: no run task in %s", ErrCommandNotFound, path)
+	}
+
+	root, dir, gradle := gradleBuild(filepath.Dir(path))
+	return inDir(root, fmt.Sprintf(`%s %s:%s --args="%s"`, gradle, gradleProject(dir), task, BashArgs)), nil
+}
+
+// BuildCommand installs the distribution of an application or assembles the
+// jar of a Spring Boot project and writes a script launching it
+func (d gradleDetector) BuildCommand(path, name string) ([]string, error) {
+	task, ok := gradleTask(path)
+	if !ok {
+		return nil, ErrCommandNotFound
+	}
+
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	root, dir, gradle := gradleBuild(filepath.Dir(path))
+	var script string
+	if task == "bootRun" {
+		script = fmt.Sprintf("%s %s:bootJar && ", gradle, gradleProject(dir)) + installScript(
+			fmt.Sprintf(`"$(ls %s | grep -v -- -plain.jar | head -n 1)"`, filepath.Join(dir, "build", "libs", "*.jar")), "",
+			`exec java -jar "%s" "$@"`, output,
+		)
+	} else {
+		// the start script needs the lib directory of the distribution
+		script = fmt.Sprintf("%s %s:installDist && ", gradle, gradleProject(dir)) + installScript(
+			fmt.Sprintf(`"$(ls %s | grep -v '\.bat$' | head -n 1)"`, filepath.Join(dir, "build", "install", "*", "bin", "*")),
+			`"$(dirname "$(dirname "$artifact")")"`,
+			`exec "%s" "$@"`, output,
+		)
+	}
+	return []string{"bash", "-c", inDir(root, script)}, nil
+}
+
+// gradleTask returns the task launching the project of the build file
+func gradleTask(path string) (string, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case SpringBootDecl.Match(content):
+		return "bootRun", true
+	case GradleApplicationDecl.Match(content):
+		return "run", true
+	default:
+		return "", false
+	}
+}
+
+// gradleBuild returns the root of the build relative to the working
+// directory, the project directory relative to the root and the Gradle
+// command of the build
+func gradleBuild(dir string) (string, string, string) {
+	project, err := filepath.Abs(dir)
+	if err != nil {
+		return dir, ".", "gradle"
+	}
+
+	root := project
+	if settings, ok := findUpInRepo(project, gradleSettingsFiles...); ok {
+		root = settings
+	}
+
+	gradle := "gradle"
+	if _, err := os.Stat(filepath.Join(root, "gradlew")); err == nil {
+		gradle = "./gradlew"
+	}
+
+	rel, err := filepath.Rel(root, project)
+	if err != nil {
+		rel = "."
+	}
+	if relRoot, err := relativeToWd(root); err == nil {
+		root = relRoot
+	}
+	return root, rel, gradle
+}
+
+// gradleProject is the path of the project in the build, e.g. :services:api
+func gradleProject(dir string) string {
+	if dir == "." {
+		return ""
+	}
+	return ":" + strings.ReplaceAll(filepath.ToSlash(dir), "/", ":")
+}
+
+func (mavenDetector) Name() Extension { return PomXML }
+
+func (mavenDetector) Match(path string, info fs.FileInfo) bool {
+	if info.Name() != PomXML {
+		return false
+	}
+	_, ok := mavenPlugin(path)
+	return ok
+}
+
+func (mavenDetector) RunCommand(path string) (string, error) {
+	plugin, ok := mavenPlugin(path)
+	if !ok {
+		return "", fmt.Errorf("%w: no launching plugin in %s", ErrCommandNotFound, path)
+	}
+
+	dir := filepath.Dir(path)
+	if plugin == "spring-boot" {
+		return inDir(dir, fmt.Sprintf(`%s spring-boot:run -Dspring-boot.run.arguments="%s"`, mavenCommand(dir), BashArgs)), nil
+	}
+	return inDir(dir, fmt.Sprintf(`%s -q compile exec:java -Dexec.args="%s"`, mavenCommand(dir), BashArgs)), nil
+}
+
+// BuildCommand packages a Spring Boot jar or the jar of an exec project
+// with its dependencies and writes a script launching it
+func (mavenDetector) BuildCommand(path, name string) ([]string, error) {
+	plugin, ok := mavenPlugin(path)
+	if !ok {
+		return nil, ErrCommandNotFound
+	}
+
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	mvn := mavenCommand(filepath.Dir(path))
+	if plugin == "spring-boot" {
+		script := mvn + " -DskipTests package && " +
+			installScript(`"$(ls target/*.jar | head -n 1)"`, "", `exec java -jar "%s" "$@"`, output)
+		return []string{"bash", "-c", inDir(filepath.Dir(path), script)}, nil
+	}
+
+	mainClass, ok := mavenMainClass(path)
+	if !ok {
+		return nil, fmt.Errorf("%w: no main class in %s", ErrCommandNotFound, path)
+	}
+	script := mvn + " -q -DskipTests package dependency:copy-dependencies -DincludeScope=runtime " +
+		"-DoutputDirectory=target/lib && " +
+		`cp "$(ls target/*.jar | head -n 1)" target/lib/ && ` +
+		installScript("target/lib", "", `exec java -cp "%s/*" `+mainClass+` "$@"`, output)
+	return []string{"bash", "-c", inDir(filepath.Dir(path), script)}, nil
+}
+
+// mavenMainClass returns the main class of the exec plugin
+func mavenMainClass(path string) (string, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	m := MainClassDecl.FindSubmatch(content)
+	if m == nil {
+		return "", false
+	}
+	return string(m[1]), true
+}
+
+// mavenPlugin returns the plugin launching the project of the pom file
+func mavenPlugin(path string) (string, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case SpringBootDecl.Match(content):
+		return "spring-boot", true
+	case MavenExecDecl.Match(content):
+		return "exec", true
+	default:
+		return "", false
+	}
+}
+
+// mavenCommand prefers the Maven wrapper of the build, modules use the one
+// of the parent project
+func mavenCommand(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "mvn"
+	}
+
+	root, ok := findUpInRepo(absDir, "mvnw")
+	if !ok {
+		return "mvn"
+	}
+	rel, err := filepath.Rel(absDir, root)
+	if err != nil {
+		return "mvn"
+	}
+	return packagePath(filepath.Join(rel, "mvnw"))
+}
+
+func (d jvmSourceDetector) Name() Extension { return d.extension }
+
+func (d jvmSourceDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != d.extension {
+		return false
+	}
+
+	files := append([]Filename{PomXML}, gradleBuildFiles...)
+	if _, inProject := findUpInRepo(filepath.Dir(path), files...); inProject {
+		return false
+	}
+	return hasMain(path, d.mainDecl)
+}
+
+func (d jvmSourceDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf(d.run, path), nil
+}
+
+func (d jvmSourceDetector) BuildCommand(path, name string) ([]string, error) {
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.compile == "" {
+		return []string{"bash", "-c", installScript(shellQuote(path), "", `exec java "%s" "$@"`, output)}, nil
+	}
+	return []string{"bash", "-c", fmt.Sprintf(d.compile, path) + " && " +
+		installScript("main.jar", "", `exec java -jar "%s" "$@"`, output)}, nil
+}
+
+// installScript copies the built artifact to the data directory of the
+// executable, as the build directory may be a temporary clone, and writes a
+// script at output launching the copy, %s in launch is its path. The whole
+// root directory of the artifact is copied instead if root is not empty,
+// root may refer to the artifact as $artifact.
+func installScript(artifact, root, launch, output string) string {
+	if root == "" {
+		root = `"$artifact"`
+	}
+	return fmt.Sprintf(
+		`artifact=%s && root=%s && data="${XDG_DATA_HOME:-$HOME/.local/share}/auto-launcher/"%s && `+
+			`rm -rf "$data" && mkdir -p "$data" && cp -R "$root" "$data/" && `+
+			`copy="$data/$(basename "$root")${artifact#"$root"}" && `+
+			`printf '#!/bin/sh\n%s\n' "$copy" > %s && chmod +x %s`,
+		artifact, root, shellQuote(filepath.Base(output)), launch, shellQuote(output), shellQuote(output),
+	)
+}
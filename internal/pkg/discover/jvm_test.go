@@ -0,0 +1,115 @@
+package discover
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJVMProjects(t *testing.T) {
+	writeTree(t, map[string]string{
+		"gradle/settings.gradle.kts":           `rootProject.name = "shop"`,
+		"gradle/gradlew":                       "",
+		"gradle/services/api/build.gradle.kts": "plugins {\n    application\n}\n",
+		"gradle/services/api/src/Main.java":    "public static void main(String[] args) {}",
+		"gradle/services/billing/build.gradle": "plugins { id 'org.springframework.boot' version '3.1.0' }",
+		"gradle/libs/common/build.gradle.kts":  "plugins { `java-library` }",
+		"maven/pom.xml":                        "<artifactId>exec-maven-plugin</artifactId><mainClass>shop.App</mainClass>",
+		"maven/mvnw":                           "",
+		"maven/web/pom.xml":                    "<artifactId>spring-boot-maven-plugin</artifactId>",
+		"scripts/Hello.java":                   "class Hello { public static void main(String[] args) {} }",
+		"scripts/tool.kt":                      "fun main() {}",
+		"scripts/lib.kt":                       "class A { fun main() {} }",
+		"parent/pom.xml":                       "<artifactId>parent</artifactId>",
+		"parent/checkout/.git/description.txt": "checkout",
+		"parent/checkout/Tool.java":            "class Tool { public static void main(String[] args) {} }",
+	})
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		"build.gradle":     {"gradle/services/billing/build.gradle"},
+		"build.gradle.kts": {"gradle/services/api/build.gradle.kts"},
+		PomXML:             {"maven/pom.xml", "maven/web/pom.xml"},
+		JavaExtension:      {"parent/checkout/Tool.java", "scripts/Hello.java"},
+		KotlinExtension:    {"scripts/tool.kt"},
+	}, got)
+
+	output, err := filepath.Abs("app")
+	require.NoError(t, err)
+	install := func(artifact, root, launch string) string {
+		return `artifact=` + artifact + ` && root=` + root + ` && ` +
+			`data="${XDG_DATA_HOME:-$HOME/.local/share}/auto-launcher/"app && ` +
+			`rm -rf "$data" && mkdir -p "$data" && cp -R "$root" "$data/" && ` +
+			`copy="$data/$(basename "$root")${artifact#"$root"}" && ` +
+			`printf '#!/bin/sh\n` + launch + `\n' "$copy" > ` + output + ` && chmod +x ` + output
+	}
+
+	tests := []struct {
+		path      string
+		ext       string
+		wantRun   string
+		wantBuild string
+	}{
+		{
+			path:    "gradle/services/api/build.gradle.kts",
+			ext:     "build.gradle.kts",
+			wantRun: `cd gradle && ./gradlew :services:api:run --args="$*"`,
+			wantBuild: `cd gradle && ./gradlew :services:api:installDist && ` + install(
+				`"$(ls services/api/build/install/*/bin/* | grep -v '\.bat$' | head -n 1)"`,
+				`"$(dirname "$(dirname "$artifact")")"`, `exec "%s" "$@"`,
+			),
+		},
+		{
+			path:    "gradle/services/billing/build.gradle",
+			ext:     "build.gradle",
+			wantRun: `cd gradle && ./gradlew :services:billing:bootRun --args="$*"`,
+			wantBuild: `cd gradle && ./gradlew :services:billing:bootJar && ` + install(
+				`"$(ls services/billing/build/libs/*.jar | grep -v -- -plain.jar | head -n 1)"`,
+				`"$artifact"`, `exec java -jar "%s" "$@"`,
+			),
+		},
+		{
+			path:    "maven/pom.xml",
+			ext:     PomXML,
+			wantRun: `cd maven && ./mvnw -q compile exec:java -Dexec.args="$*"`,
+			wantBuild: `cd maven && ./mvnw -q -DskipTests package dependency:copy-dependencies -DincludeScope=runtime ` +
+				`-DoutputDirectory=target/lib && cp "$(ls target/*.jar | head -n 1)" target/lib/ && ` +
+				install(`target/lib`, `"$artifact"`, `exec java -cp "%s/*" shop.App "$@"`),
+		},
+		{
+			path:    "maven/web/pom.xml",
+			ext:     PomXML,
+			wantRun: `cd maven/web && ../mvnw spring-boot:run -Dspring-boot.run.arguments="$*"`,
+			wantBuild: `cd maven/web && ../mvnw -DskipTests package && ` +
+				install(`"$(ls target/*.jar | head -n 1)"`, `"$artifact"`, `exec java -jar "%s" "$@"`),
+		},
+		{
+			path:      "scripts/Hello.java",
+			ext:       JavaExtension,
+			wantRun:   "java scripts/Hello.java $*",
+			wantBuild: install(`scripts/Hello.java`, `"$artifact"`, `exec java "%s" "$@"`),
+		},
+		{
+			path:    "scripts/tool.kt",
+			ext:     KotlinExtension,
+			wantRun: "kotlinc scripts/tool.kt -include-runtime -d main.jar && java -jar main.jar $*",
+			wantBuild: `kotlinc scripts/tool.kt -include-runtime -d main.jar && ` +
+				install(`main.jar`, `"$artifact"`, `exec java -jar "%s" "$@"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			run, err := prepareCommand(config.Config{}, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+
+			build, err := prepareBuildCommand(config.Config{}, tt.ext, tt.path, "app")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bash", "-c", tt.wantBuild}, build)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	RubyMainDecl    = regexp.MustCompile(`(?m)^\s*(if|unless)\s+(__FILE__\s*==\s*\$(0|PROGRAM_NAME)|\$(0|PROGRAM_NAME)\s*==\s*__FILE__)`)
+	PHPMainDecl     = regexp.MustCompile(`\bPHP_SAPI\b|\bphp_sapi_name\s*\(|\$argv\b`)
+	LuaModuleDecl   = regexp.MustCompile(`(?m)^return\s+[\w{]`)
+	ElixirMainDecl  = regexp.MustCompile(`(?s)defmodule\s+([\w.]+)\s+do\b.*?\bdef\s+main\s*\(`)
+	HaskellMainDecl = regexp.MustCompile(`(?m)^main\s*::\s*IO\s*\(\s*\)`)
+	ZigMainDecl     = regexp.MustCompile(`(?m)^pub\s+fn\s+main\s*\(`)
+	NimMainDecl     = regexp.MustCompile(`(?m)^when\s+isMainModule\s*:`)
+	DenoMainDecl    = regexp.MustCompile(`import\.meta\.main\b|\bDeno\.serve\s*\(`)
+	BunMainDecl     = regexp.MustCompile(`import\.meta\.main\b|\bBun\.serve\s*\(`)
+	DenoAPIDecl     = regexp.MustCompile(`\bDeno\.`)
+)
+
+type (
+	// interpretedDetector launches scripts of an interpreted language. A
+	// file is a script if it has a #! line or the main declaration, every
+	// file is if there is no declaration, and files with the module
+	// declaration are skipped.
+	interpretedDetector struct {
+		runOnly
+		extension   Extension
+		interpreter string
+		mainDecl    *regexp.Regexp
+		moduleDecl  *regexp.Regexp
+	}
+
+	// rubyDetector runs scripts with the bundle of their project
+	rubyDetector struct{ interpretedDetector }
+
+	// elixirDetector runs .exs scripts out of mix projects by elixir and
+	// modules with a main function by mix run
+	elixirDetector struct{ runOnly }
+
+	// elixirScriptDetector runs .exs scripts out of mix projects, scripts
+	// of mix projects are their configs and tests
+	elixirScriptDetector struct{ runOnly }
+
+	// haskellDetector runs Main modules by stack or cabal in their projects
+	// and by runghc out of them
+	haskellDetector struct{}
+
+	// zigDetector runs zig build run in projects and zig run for single files
+	zigDetector struct{}
+
+	// nimDetector compiles and runs modules with an isMainModule block
+	nimDetector struct{}
+
+	// jsRuntimeDetector runs entry points of Deno and Bun, the files are
+	// left to the node detectors if they do not belong to the runtime
+	jsRuntimeDetector struct {
+		name     Extension
+		mainDecl *regexp.Regexp
+		belongs  func(path string) bool
+		run      func(path string) string
+		compile  func(path, output string) string
+	}
+)
+
+// nolint: gochecknoglobals
+var (
+	rubyScriptDetector = rubyDetector{interpretedDetector{
+		extension: RubyExtension, interpreter: "ruby", mainDecl: RubyMainDecl,
+	}}
+	perlDetector = interpretedDetector{extension: PerlExtension, interpreter: "perl"}
+	luaDetector  = interpretedDetector{extension: LuaExtension, interpreter: "lua", moduleDecl: LuaModuleDecl}
+	phpDetector  = interpretedDetector{extension: PHPExtension, interpreter: "php", mainDecl: PHPMainDecl}
+
+	jsRuntimeExtensions = map[Extension]bool{".js": true, ".mjs": true, ".ts": true, ".mts": true, ".tsx": true}
+
+	denoDetector = jsRuntimeDetector{
+		name:     "deno",
+		mainDecl: DenoMainDecl,
+		belongs: func(path string) bool {
+			if _, ok := findUpInRepo(filepath.Dir(path), bunFiles...); ok {
+				return false
+			}
+			_, ok := findUpInRepo(filepath.Dir(path), "deno.json", "deno.jsonc")
+			return ok || hasMain(path, DenoAPIDecl)
+		},
+		run: func(path string) string {
+			return fmt.Sprintf("deno run %s%s %s", denoPermissions(path), shellQuote(path), BashArgs)
+		},
+		compile: func(path, output string) string {
+			return fmt.Sprintf("deno compile %s--output %s %s", denoPermissions(path), output, shellQuote(path))
+		},
+	}
+	bunDetector = jsRuntimeDetector{
+		name:     "bun",
+		mainDecl: BunMainDecl,
+		belongs: func(path string) bool {
+			_, ok := findUpInRepo(filepath.Dir(path), bunFiles...)
+			return ok
+		},
+		run: func(path string) string {
+			return fmt.Sprintf("bun run %s %s", shellQuote(path), BashArgs)
+		},
+		compile: func(path, output string) string {
+			return fmt.Sprintf("bun build --compile --outfile %s %s", output, shellQuote(path))
+		},
+	}
+
+	bunFiles = []Filename{"bun.lockb", "bun.lock", "bunfig.toml"}
+
+	// denoPermissionDecls grant only what the script uses
+	denoPermissionDecls = []struct {
+		flag string
+		decl *regexp.Regexp
+	}{
+		{"--allow-env", regexp.MustCompile(`\bDeno\.env\b`)},
+		{"--allow-net", regexp.MustCompile(`\bfetch\s*\(|\bDeno\.(serve|listen|connect)\w*\s*\(|\bWebSocket\b`)},
+		{"--allow-read", regexp.MustCompile(`\bDeno\.(read\w*|open|stat|lstat|readDir)\w*\s*\(`)},
+		{"--allow-write", regexp.MustCompile(`\bDeno\.(write\w*|mkdir|remove|rename|create)\w*\s*\(`)},
+		{"--allow-run", regexp.MustCompile(`\bDeno\.(Command|run)\b`)},
+	}
+)
+
+func (d interpretedDetector) Name() Extension { return d.extension }
+
+func (d interpretedDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != d.extension {
+		return false
+	}
+
+	content, err := readPrefix(path)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case d.moduleDecl != nil && d.moduleDecl.Match(content):
+		return false
+	case d.mainDecl == nil || strings.HasPrefix(string(content), "#!"):
+		return true
+	default:
+		return d.mainDecl.Match(content)
+	}
+}
+
+func (d interpretedDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf("%s %s %s", d.interpreter, shellQuote(path), BashArgs), nil
+}
+
+func (d rubyDetector) RunCommand(path string) (string, error) {
+	if _, ok := findUpInRepo(filepath.Dir(path), "Gemfile"); ok {
+		return fmt.Sprintf("bundle exec ruby %s %s", shellQuote(path), BashArgs), nil
+	}
+	return d.interpretedDetector.RunCommand(path)
+}
+
+func (elixirDetector) Name() Extension { return ElixirExtension }
+
+func (elixirDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != ElixirExtension {
+		return false
+	}
+	_, ok := findUpInRepo(filepath.Dir(path), MixFile)
+	return ok && hasMain(path, ElixirMainDecl)
+}
+
+// RunCommand calls main of the module with the launcher arguments
+func (elixirDetector) RunCommand(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	m := ElixirMainDecl.FindSubmatch(content)
+	if len(m) == 0 {
+		return "", ErrCommandNotFound
+	}
+
+	root, _ := findUpInRepo(filepath.Dir(path), MixFile)
+	if root, err = relativeToWd(root); err != nil {
+		return "", err
+	}
+	return inDir(root, fmt.Sprintf("mix run -e '%s.main(System.argv())' -- %s", m[1], BashArgs)), nil
+}
+
+func (elixirScriptDetector) Name() Extension { return ElixirScriptExtension }
+
+func (elixirScriptDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != ElixirScriptExtension {
+		return false
+	}
+	_, inProject := findUpInRepo(filepath.Dir(path), MixFile)
+	return !inProject
+}
+
+func (elixirScriptDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf("elixir %s %s", shellQuote(path), BashArgs), nil
+}
+
+func (haskellDetector) Name() Extension { return HaskellExtension }
+
+func (haskellDetector) Match(path string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == HaskellExtension && hasMain(path, HaskellMainDecl)
+}
+
+func (haskellDetector) RunCommand(path string) (string, error) {
+	root, tool, ok := haskellProject(path)
+	if !ok {
+		return fmt.Sprintf("runghc %s %s", shellQuote(path), BashArgs), nil
+	}
+	return inDir(root, fmt.Sprintf("%s run -- %s", tool, BashArgs)), nil
+}
+
+// BuildCommand compiles single files, projects are installed by their tools
+func (haskellDetector) BuildCommand(path, name string) ([]string, error) {
+	if _, _, ok := haskellProject(path); ok {
+		return nil, ErrCommandNotFound
+	}
+
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"ghc", "-O2", "-o", output, path}, nil
+}
+
+// haskellProject returns the root of the stack or cabal project of the file
+// and the tool to run it with
+func haskellProject(path string) (string, string, bool) {
+	dir := filepath.Dir(path)
+	root, tool := "", ""
+	if stackRoot, ok := findUpInRepo(dir, "stack.yaml"); ok {
+		root, tool = stackRoot, "stack"
+	} else if cabalRoot, ok := findUpInRepo(dir, "cabal.project", "*.cabal"); ok {
+		root, tool = cabalRoot, "cabal -v0"
+	} else {
+		return "", "", false
+	}
+
+	root, err := relativeToWd(root)
+	return root, tool, err == nil
+}
+
+func (zigDetector) Name() Extension { return ZigExtension }
+
+func (zigDetector) Match(path string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == ZigExtension && hasMain(path, ZigMainDecl)
+}
+
+func (zigDetector) RunCommand(path string) (string, error) {
+	if root, ok := findUpInRepo(filepath.Dir(path), BuildZig); ok {
+		root, err := relativeToWd(root)
+		if err != nil {
+			return "", err
+		}
+		return inDir(root, "zig build run -- "+BashArgs), nil
+	}
+	return fmt.Sprintf("zig run %s -- %s", shellQuote(path), BashArgs), nil
+}
+
+// BuildCommand compiles single files, build.zig decides on executables of
+// projects
+func (zigDetector) BuildCommand(path, name string) ([]string, error) {
+	if _, ok := findUpInRepo(filepath.Dir(path), BuildZig); ok {
+		return nil, ErrCommandNotFound
+	}
+
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"zig", "build-exe", "-O", "ReleaseSafe", "-femit-bin=" + output, path}, nil
+}
+
+func (nimDetector) Name() Extension { return NimExtension }
+
+func (nimDetector) Match(path string, info fs.FileInfo) bool {
+	return filepath.Ext(info.Name()) == NimExtension && hasMain(path, NimMainDecl)
+}
+
+func (nimDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf("nim c -r --hints:off %s %s", shellQuote(path), BashArgs), nil
+}
+
+func (nimDetector) BuildCommand(path, name string) ([]string, error) {
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"nim", "c", "-d:release", "--hints:off", "-o:" + output, path}, nil
+}
+
+func (d jsRuntimeDetector) Name() Extension { return d.name }
+
+func (d jsRuntimeDetector) Match(path string, info fs.FileInfo) bool {
+	return jsRuntimeExtensions[filepath.Ext(info.Name())] && hasMain(path, d.mainDecl) && d.belongs(path)
+}
+
+func (d jsRuntimeDetector) RunCommand(path string) (string, error) {
+	return d.run(path), nil
+}
+
+func (d jsRuntimeDetector) BuildCommand(path, name string) ([]string, error) {
+	output, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"bash", "-c", d.compile(path, output)}, nil
+}
+
+// denoPermissions returns flags for the Deno APIs the script calls
+func denoPermissions(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	var flags strings.Builder
+	for _, p := range denoPermissionDecls {
+		if p.decl.Match(content) {
+			flags.WriteString(p.flag + " ")
+		}
+	}
+	return flags.String()
+}
@@ -0,0 +1,145 @@
+package discover
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguages(t *testing.T) {
+	rootPath := writeTree(t, map[string]string{
+		"ruby/Gemfile":                  "source 'https://rubygems.org'",
+		"ruby/bin/console":              "#!/usr/bin/env ruby\nrequire 'irb'\n",
+		"ruby/bin/setup.rb":             "#!/usr/bin/env ruby\nsystem('bundle')\n",
+		"ruby/gem/.git/description.txt": "gem",
+		"ruby/gem/check.rb":             "if __FILE__ == $0\n  puts 1\nend\n",
+		"mix/mix.exs":                   "defmodule Cli.MixProject do\nend\n",
+		"mix/lib/cli.ex":                "defmodule Cli do\n  def main(args) do\n    IO.inspect(args)\n  end\nend\n",
+		"mix/test/cli_test.exs":         "ExUnit.start()",
+		"stack/stack.yaml":              "resolver: lts-20.0",
+		"stack/app/Main.hs":             "main :: IO ()\nmain = pure ()\n",
+		"cabal/hello.cabal":             "name: hello",
+		"cabal/app/Main.hs":             "main :: IO ()\nmain = pure ()\n",
+		"zig/build.zig":                 "const std = @import(\"std\");",
+		"zig/src/main.zig":              "pub fn main() !void {}",
+		"deno/deno.json":                "{}",
+		"deno/main.ts":                  "if (import.meta.main) {\n  const data = await Deno.readTextFile(Deno.env.get(\"CONFIG\")!);\n  await fetch(data);\n}\n",
+		"bun/package.json":              `{"name": "api"}`,
+		"bun/bun.lockb":                 "",
+		"bun/src/server.ts":             "Bun.serve({ fetch: () => new Response('ok') });",
+		"bun/src/routes.ts":             "export const routes = [];",
+		"scripts/report.nim":            "when isMainModule:\n  echo 1\n",
+		"scripts/db/migrate.ts":         "if (import.meta.main) { await Deno.remove('db.lock'); }",
+		"scripts/cron/cleanup.rb":       "puts 1 if __FILE__ == $PROGRAM_NAME\nunless $0 == __FILE__ then end\n",
+	})
+
+	// manifests are not executables unlike the files of bin
+	require.NoError(t, os.Chmod(path.Join(rootPath, "ruby/Gemfile"), 0644))
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		RubyExtension:    {"ruby/bin/setup.rb", "ruby/gem/check.rb", "scripts/cron/cleanup.rb"},
+		ShebangName:      {"ruby/bin/console"},
+		ElixirExtension:  {"mix/lib/cli.ex"},
+		HaskellExtension: {"cabal/app/Main.hs", "stack/app/Main.hs"},
+		ZigExtension:     {"zig/src/main.zig"},
+		NimExtension:     {"scripts/report.nim"},
+		"deno":           {"deno/main.ts", "scripts/db/migrate.ts"},
+		"bun":            {"bun/src/server.ts"},
+	}, got)
+
+	output, err := filepath.Abs("app")
+	require.NoError(t, err)
+
+	tests := []struct {
+		path      string
+		ext       string
+		wantRun   string
+		wantBuild []string
+	}{
+		{
+			path:    "ruby/bin/setup.rb",
+			ext:     RubyExtension,
+			wantRun: "bundle exec ruby ruby/bin/setup.rb $*",
+		},
+		{
+			path:    "ruby/gem/check.rb",
+			ext:     RubyExtension,
+			wantRun: "ruby ruby/gem/check.rb $*",
+		},
+		{
+			path:    "scripts/cron/cleanup.rb",
+			ext:     RubyExtension,
+			wantRun: "ruby scripts/cron/cleanup.rb $*",
+		},
+		{
+			path:    "mix/lib/cli.ex",
+			ext:     ElixirExtension,
+			wantRun: "cd mix && mix run -e 'Cli.main(System.argv())' -- $*",
+		},
+		{
+			path:    "stack/app/Main.hs",
+			ext:     HaskellExtension,
+			wantRun: "cd stack && stack run -- $*",
+		},
+		{
+			path:    "cabal/app/Main.hs",
+			ext:     HaskellExtension,
+			wantRun: "cd cabal && cabal -v0 run -- $*",
+		},
+		{
+			path:    "zig/src/main.zig",
+			ext:     ZigExtension,
+			wantRun: "cd zig && zig build run -- $*",
+		},
+		{
+			path:      "scripts/report.nim",
+			ext:       NimExtension,
+			wantRun:   "nim c -r --hints:off scripts/report.nim $*",
+			wantBuild: []string{"nim", "c", "-d:release", "--hints:off", "-o:" + output, "scripts/report.nim"},
+		},
+		{
+			path:    "deno/main.ts",
+			ext:     "deno",
+			wantRun: "deno run --allow-env --allow-net --allow-read deno/main.ts $*",
+			wantBuild: []string{
+				"bash", "-c", "deno compile --allow-env --allow-net --allow-read --output " + output + " deno/main.ts",
+			},
+		},
+		{
+			path:    "scripts/db/migrate.ts",
+			ext:     "deno",
+			wantRun: "deno run --allow-write scripts/db/migrate.ts $*",
+			wantBuild: []string{
+				"bash", "-c", "deno compile --allow-write --output " + output + " scripts/db/migrate.ts",
+			},
+		},
+		{
+			path:      "bun/src/server.ts",
+			ext:       "bun",
+			wantRun:   "bun run bun/src/server.ts $*",
+			wantBuild: []string{"bash", "-c", "bun build --compile --outfile " + output + " bun/src/server.ts"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			run, err := prepareCommand(config.Config{}, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+
+			build, err := prepareBuildCommand(config.Config{}, tt.ext, tt.path, "app")
+			if tt.wantBuild == nil {
+				require.ErrorIs(t, err, ErrCommandNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBuild, build)
+		})
+	}
+}
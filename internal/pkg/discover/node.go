@@ -0,0 +1,211 @@
+package discover
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// binTargetPrefix marks targets of package.json made of the bin field
+const binTargetPrefix = "bin:"
+
+var ModuleExportDecl = regexp.MustCompile(`(?m)^\s*export\s`)
+
+type (
+	// nodeDetector offers scripts and executables of package.json, scripts
+	// are launched by the package manager of the project
+	nodeDetector struct{ runOnly }
+
+	// nodeScriptDetector launches JavaScript and TypeScript files out of
+	// node packages, files of a package are started by its scripts unless it
+	// has none. Files exporting anything are modules of other scripts if
+	// skipModules is set.
+	nodeScriptDetector struct {
+		runOnly
+		extension   Extension
+		runner      string
+		skipModules bool
+	}
+
+	packageJSON struct {
+		Name           string            `json:"name"`
+		Scripts        map[string]string `json:"scripts"`
+		Bin            json.RawMessage   `json:"bin"`
+		PackageManager string            `json:"packageManager"`
+	}
+
+	// packageManager runs scripts of package.json, args are appended
+	packageManager struct {
+		name string
+		run  string
+	}
+)
+
+// nolint: gochecknoglobals
+var (
+	npm  = packageManager{name: "npm", run: "npm run %s -- " + BashArgs}
+	yarn = packageManager{name: "yarn", run: "yarn run %s " + BashArgs}
+	pnpm = packageManager{name: "pnpm", run: "pnpm run %s " + BashArgs}
+	bun  = packageManager{name: "bun", run: "bun run %s " + BashArgs}
+
+	// lockfiles tell the package manager of a project
+	lockfiles = []struct {
+		name    string
+		manager packageManager
+	}{
+		{"bun.lockb", bun},
+		{"bun.lock", bun},
+		{"pnpm-lock.yaml", pnpm},
+		{"yarn.lock", yarn},
+		{"package-lock.json", npm},
+		{"npm-shrinkwrap.json", npm},
+	}
+)
+
+func (nodeDetector) Name() Extension { return PackageJSON }
+
+func (d nodeDetector) Match(path string, info fs.FileInfo) bool {
+	return info.Name() == PackageJSON && len(d.Targets(path)) > 0
+}
+
+// Targets are scripts in alphabetical order followed by bin:<name> targets
+func (nodeDetector) Targets(path string) []string {
+	pkg, err := readPackageJSON(path)
+	if err != nil {
+		return nil
+	}
+
+	targets := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+
+	bins := pkg.bins()
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targets = append(targets, binTargetPrefix+name)
+	}
+	return targets
+}
+
+func (nodeDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+	pkg, err := readPackageJSON(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Dir(path)
+	if strings.HasPrefix(target, binTargetPrefix) {
+		bin, ok := pkg.bins()[strings.TrimPrefix(target, binTargetPrefix)]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrCommandNotFound, target)
+		}
+		return fmt.Sprintf("node %s %s", filepath.Join(dir, bin), BashArgs), nil
+	}
+
+	if target == "" {
+		target = "start"
+	}
+	if _, ok := pkg.Scripts[target]; !ok {
+		return "", fmt.Errorf("%w: script %s", ErrCommandNotFound, target)
+	}
+
+	command := detectPackageManager(dir, pkg).command(target)
+	if dir != "." {
+		command = fmt.Sprintf("cd %s && %s", shellQuote(dir), command)
+	}
+	return command, nil
+}
+
+func (d nodeScriptDetector) Name() Extension { return d.extension }
+
+func (d nodeScriptDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != d.extension {
+		return false
+	}
+	// files of a package with scripts or executables are started by them
+	if dir, inPackage := findUpInRepo(filepath.Dir(path), PackageJSON); inPackage {
+		if len(nodeDetector{}.Targets(filepath.Join(dir, PackageJSON))) > 0 {
+			return false
+		}
+	}
+	return !d.skipModules || !hasMain(path, ModuleExportDecl)
+}
+
+func (d nodeScriptDetector) RunCommand(path string) (string, error) {
+	return fmt.Sprintf("%s %s %s", d.runner, path, BashArgs), nil
+}
+
+func (m packageManager) command(script string) string {
+	return fmt.Sprintf(m.run, shellQuote(script))
+}
+
+// bins maps executable names to their files, a single file is named after
+// the package without its scope
+func (pkg packageJSON) bins() map[string]string {
+	var single string
+	if err := json.Unmarshal(pkg.Bin, &single); err == nil && single != "" {
+		return map[string]string{path.Base(pkg.Name): single}
+	}
+
+	var bins map[string]string
+	_ = json.Unmarshal(pkg.Bin, &bins)
+	return bins
+}
+
+// detectPackageManager prefers the packageManager field of package.json,
+// then the nearest lockfile, workspaces keep it in their root, and falls
+// back to npm
+func detectPackageManager(dir string, pkg packageJSON) packageManager {
+	name := strings.SplitN(pkg.PackageManager, "@", 2)[0] // nolint: gomnd
+	for _, manager := range []packageManager{npm, yarn, pnpm, bun} {
+		if manager.name == name {
+			return manager
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return npm
+	}
+
+	for {
+		for _, lockfile := range lockfiles {
+			if _, err := os.Stat(filepath.Join(dir, lockfile.name)); err == nil {
+				return lockfile.manager
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return npm
+		}
+		dir = parent
+	}
+}
+
+func readPackageJSON(path string) (packageJSON, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return packageJSON{}, err
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return packageJSON{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return pkg, nil
+}
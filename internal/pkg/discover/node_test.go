@@ -0,0 +1,107 @@
+package discover
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeDetectors(t *testing.T) {
+	writeTree(t, map[string]string{
+		"web/package.json": `{
+			"name": "@acme/web",
+			"bin": "cli.js",
+			"scripts": {"dev": "vite", "build:prod": "vite build"}
+		}`,
+		"web/pnpm-lock.yaml":              "",
+		"web/cli.js":                      "#!/usr/bin/env node",
+		"web/src/main.ts":                 "console.log(1)",
+		"web/node_modules/x/package.json": `{"scripts": {"test": "jest"}}`,
+		"tools/package.json":              `{"bin": {"gen": "bin/gen.js"}}`,
+		"scripts/seed.mts":                "await seed()",
+		"scripts/db.ts":                   "export const db = 1",
+		"scripts/hello.js":                "console.log('hello')",
+		"lib/package.json":                `{"name": "lib"}`,
+		"lib/run.js":                      "console.log('run')",
+		"outer/package.json":              `{"scripts": {"lint": "eslint"}}`,
+		"outer/repo/.git/HEAD":            "ref: refs/heads/main",
+		"outer/repo/app.js":               "console.log('app')",
+	})
+
+	cfg := config.Config{
+		SkipPaths: []string{".git", "node_modules"},
+		Commands:  map[string]config.Commands{TSModuleExtension: {Run: "bun {path} {args}"}},
+	}
+	got, err := getExecutables(".", cfg)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		PackageJSON: {
+			"outer/package.json:lint",
+			"tools/package.json:bin:gen",
+			"web/package.json:build:prod",
+			"web/package.json:dev",
+			"web/package.json:bin:web",
+		},
+		JavaScriptExtension: {"lib/run.js", "outer/repo/app.js", "scripts/hello.js"},
+		TSModuleExtension:   {"scripts/seed.mts"},
+		ShebangName:         {"web/cli.js"},
+	}, got)
+
+	tests := []struct {
+		path string
+		ext  string
+		want string
+	}{
+		{path: "web/package.json:dev", ext: PackageJSON, want: "cd web && pnpm run dev $*"},
+		{path: "web/package.json:build:prod", ext: PackageJSON, want: "cd web && pnpm run build:prod $*"},
+		{path: "web/package.json:bin:web", ext: PackageJSON, want: "node web/cli.js $*"},
+		{path: "tools/package.json:bin:gen", ext: PackageJSON, want: "node tools/bin/gen.js $*"},
+		{path: "scripts/hello.js", ext: JavaScriptExtension, want: "node scripts/hello.js $*"},
+		{path: "scripts/seed.mts", ext: TSModuleExtension, want: "bun scripts/seed.mts $*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			command, err := prepareCommand(cfg, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, command)
+		})
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		pkg   packageJSON
+		want  string
+	}{
+		{name: "no lockfile", want: "npm run start -- $*"},
+		{name: "npm", files: []string{"package-lock.json"}, want: "npm run start -- $*"},
+		{name: "yarn", files: []string{"yarn.lock"}, want: "yarn run start $*"},
+		{name: "pnpm", files: []string{"pnpm-lock.yaml"}, want: "pnpm run start $*"},
+		{name: "bun", files: []string{"bun.lockb"}, want: "bun run start $*"},
+		{name: "workspace root", files: []string{"../yarn.lock"}, want: "yarn run start $*"},
+		{
+			name:  "packageManager field",
+			files: []string{"package-lock.json"},
+			pkg:   packageJSON{PackageManager: "pnpm@8.6.0"},
+			want:  "pnpm run start $*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for _, filename := range tt.files {
+				files[path.Join("app", filename)] = ""
+			}
+			dir := path.Join(writeTree(t, files), "app")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+
+			assert.Equal(t, tt.want, detectPackageManager(dir, tt.pkg).command("start"))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package discover
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+// ignoreFiles are read in every directory if the config respects them
+var ignoreFiles = []string{".gitignore", ".ignore"}
+
+// pathFilter decides which paths of the walk are looked at. Patterns without
+// a slash match base names of paths, the others match paths relative to the
+// walk root, both support doublestar globs.
+type pathFilter struct {
+	root           string
+	skipPaths      []string
+	includePaths   []string
+	ignoreFiles    bool
+	ignorePatterns []gitignore.Pattern
+}
+
+func newPathFilter(root string, cfg config.Config) *pathFilter {
+	return &pathFilter{
+		root:         root,
+		skipPaths:    cfg.SkipPaths,
+		includePaths: cfg.IncludePaths,
+		ignoreFiles:  cfg.RespectGitignore,
+	}
+}
+
+// skipped reports whether the walk should not go into the path
+func (f *pathFilter) skipped(path string, isDir bool) bool {
+	rel, ok := f.relative(path)
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range f.skipPaths {
+		if matchPath(pattern, rel) {
+			return true
+		}
+	}
+
+	if len(f.ignorePatterns) > 0 {
+		return gitignore.NewMatcher(f.ignorePatterns).Match(strings.Split(rel, "/"), isDir)
+	}
+	return false
+}
+
+// included reports whether the file is allowed by include paths, the file
+// is allowed if it or one of its parent directories matches
+func (f *pathFilter) included(path string) bool {
+	rel, ok := f.relative(path)
+	if len(f.includePaths) == 0 || !ok {
+		return true
+	}
+
+	for ; rel != "."; rel = filepath.ToSlash(filepath.Dir(rel)) {
+		for _, pattern := range f.includePaths {
+			if matchPath(pattern, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enter reads ignore files of the directory, their patterns apply to the
+// directory content only
+func (f *pathFilter) enter(dir string) {
+	if !f.ignoreFiles {
+		return
+	}
+
+	rel, ok := f.relative(dir)
+	if !ok {
+		return
+	}
+
+	var domain []string
+	if rel != "." {
+		domain = strings.Split(rel, "/")
+	}
+
+	for _, name := range ignoreFiles {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "#") && strings.TrimSpace(line) != "" {
+				f.ignorePatterns = append(f.ignorePatterns, gitignore.ParsePattern(line, domain))
+			}
+		}
+		file.Close()
+	}
+}
+
+func (f *pathFilter) relative(path string) (string, bool) {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func matchPath(pattern, rel string) bool {
+	if rel == "." {
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		rel = filepath.Base(rel)
+	}
+	ok, _ := doublestar.Match(pattern, rel)
+	return ok
+}
@@ -0,0 +1,87 @@
+package discover
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFilter(t *testing.T) {
+	const goMain = "package main\n\nfunc main() {}\n"
+
+	rootPath := writeTree(t, map[string]string{
+		"main.go":                      goMain,
+		"main_test.go":                 goMain,
+		"cmd/app/main.go":              goMain,
+		"examples/a/vendor/x/main.go":  goMain,
+		"examples/a/main.go":           goMain,
+		"third_party/lib/main.go":      goMain,
+		"internal/tool/tool_test.go":   goMain,
+		"build/gen/main.go":            goMain,
+		"scripts/run.sh":               "echo",
+		"scripts/keep.sh":              "echo",
+		".gitignore":                   "build/\n# comment\n",
+		"scripts/.ignore":              "*.sh\n!keep.sh\n",
+		"examples/a/vendor/.gitignore": "",
+	})
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{
+			name: "base names and globs",
+			cfg: config.Config{
+				SkipPaths: []string{"examples/*/vendor", "third_party/**", "*_test.go", "scripts"},
+			},
+			want: []string{"build/gen/main.go", "cmd/app/main.go", "examples/a/main.go", "main.go"},
+		},
+		{
+			name: "doublestar skip",
+			cfg: config.Config{
+				SkipPaths: []string{"**/vendor", "**/*_test.go", "**/gen/**"},
+			},
+			want: []string{
+				"cmd/app/main.go", "examples/a/main.go", "main.go",
+				"scripts/keep.sh", "scripts/run.sh", "third_party/lib/main.go",
+			},
+		},
+		{
+			name: "include paths",
+			cfg: config.Config{
+				IncludePaths: []string{"cmd", "examples/*/main.go"},
+			},
+			want: []string{"cmd/app/main.go", "examples/a/main.go"},
+		},
+		{
+			name: "ignore files",
+			cfg: config.Config{
+				SkipPaths:        []string{"*_test.go", "vendor"},
+				RespectGitignore: true,
+			},
+			want: []string{
+				"cmd/app/main.go", "examples/a/main.go", "main.go",
+				"scripts/keep.sh", "third_party/lib/main.go",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executables, err := getExecutables(rootPath, tt.cfg)
+			require.NoError(t, err)
+
+			var got []string
+			for _, paths := range executables {
+				for _, p := range paths {
+					got = append(got, p[len(rootPath)+1:])
+				}
+			}
+			sort.Strings(got)
+			assert.EqualValues(t, tt.want, got)
+		})
+	}
+}
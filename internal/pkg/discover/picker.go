@@ -0,0 +1,56 @@
+package discover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// cancelledPickerCodes are exit codes of fuzzy finders closed without a
+// choice: 1 if nothing matches and 130 if the user quits
+var cancelledPickerCodes = []int{1, 130} // nolint: gochecknoglobals
+
+// chooseExternally runs the picker command by bash with the candidates on
+// its stdin, one per line as in the built-in chooser, and picks the one from
+// the first printed line. It returns false if the user quits without a
+// choice.
+func chooseExternally(candidates []Candidate, picker string) (Candidate, bool, error) {
+	var input, output bytes.Buffer
+	for _, c := range candidates {
+		fmt.Fprintln(&input, candidateRow(c))
+	}
+
+	cmd := exec.Command("bash", "-c", picker)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = &input, &output, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && cancelledPicker(exitErr.ExitCode()) {
+			return Candidate{}, false, nil
+		}
+		return Candidate{}, false, fmt.Errorf("picker %q failed: %w", picker, err)
+	}
+
+	line := strings.TrimSpace(strings.SplitN(output.String(), "\n", 2)[0]) // nolint: gomnd
+	if line == "" {
+		return Candidate{}, false, nil
+	}
+
+	number := strings.TrimSuffix(strings.Fields(line)[0], ".")
+	c, err := pickCandidate(candidates, number)
+	if err != nil {
+		return Candidate{}, false, fmt.Errorf("picker %q printed %q: %w", picker, line, err)
+	}
+	return c, true, nil
+}
+
+func cancelledPicker(code int) bool {
+	for _, cancelled := range cancelledPickerCodes {
+		if code == cancelled {
+			return true
+		}
+	}
+	return false
+}
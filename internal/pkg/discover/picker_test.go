@@ -0,0 +1,75 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChooseExternally(t *testing.T) {
+	candidates := []Candidate{
+		{Extension: GoExtension, Path: "cmd/server/main.go", Number: 1},
+		{Extension: BashExtension, Path: "scripts/deploy.sh", Description: "ship it", Number: 2},
+		{Extension: GoExtension, Path: "cmd/migrate/main.go", Number: 3},
+	}
+
+	tests := []struct {
+		name    string
+		picker  string
+		want    Filename
+		wantOk  bool
+		wantErr error
+	}{
+		{
+			name:   "chosen line",
+			picker: "grep migrate",
+			want:   "cmd/migrate/main.go",
+			wantOk: true,
+		},
+		{
+			name:   "description is shown",
+			picker: "grep -F '# ship it'",
+			want:   "scripts/deploy.sh",
+			wantOk: true,
+		},
+		{
+			name:   "first of several lines",
+			picker: "tail -n 2",
+			want:   "scripts/deploy.sh",
+			wantOk: true,
+		},
+		{
+			name:   "no match",
+			picker: "grep nothing",
+		},
+		{
+			name:   "quit",
+			picker: "cat >/dev/null; exit 130",
+		},
+		{
+			name:   "nothing printed",
+			picker: "cat >/dev/null",
+		},
+		{
+			name:    "unknown line",
+			picker:  "echo 7. main.go",
+			wantErr: ErrNoSuchCandidate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := chooseExternally(candidates, tt.picker)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got.Path)
+		})
+	}
+
+	_, _, err := chooseExternally(candidates, "cat >/dev/null; exit 2")
+	assert.Error(t, err, "a broken picker is an error")
+}
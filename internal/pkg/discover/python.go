@@ -0,0 +1,227 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	pythonMainFile = "__main__.py"
+	pythonInitFile = "__init__.py"
+)
+
+type (
+	// pythonDetector launches scripts with the __main__ guard and packages
+	// with __main__.py, the latter as python -m modules
+	pythonDetector struct{ runOnly }
+
+	// pyprojectDetector offers console scripts of pyproject.toml: standard
+	// [project.scripts] and scripts of Poetry and PDM
+	pyprojectDetector struct{ runOnly }
+
+	pyproject struct {
+		Project struct {
+			Scripts map[string]string `toml:"scripts"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Scripts map[string]interface{} `toml:"scripts"`
+			} `toml:"poetry"`
+			PDM struct {
+				Scripts map[string]interface{} `toml:"scripts"`
+			} `toml:"pdm"`
+		} `toml:"tool"`
+	}
+
+	// pythonEnv is the environment of a project: a virtualenv directory
+	// relative to the working directory, uv or the system python
+	pythonEnv struct {
+		venv string
+		uv   bool
+	}
+)
+
+// nolint: gochecknoglobals
+var (
+	virtualenvDirs = []string{".venv", "venv"}
+
+	// scriptRunners launch scripts of tools that manage their environments
+	scriptRunners = map[string]string{"poetry": "poetry run", "pdm": "pdm run"}
+)
+
+func (pythonDetector) Name() Extension { return PythonExtension }
+
+func (pythonDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != PythonExtension {
+		return false
+	}
+	return info.Name() == pythonMainFile || hasMain(path, PythonMainDecl)
+}
+
+func (pythonDetector) RunCommand(path string) (string, error) {
+	if filepath.Base(path) != pythonMainFile {
+		return findPythonEnv(filepath.Dir(path), ".").python(path), nil
+	}
+
+	// python -m finds the package from the directory above the top package
+	root, module := pythonModule(filepath.Dir(path))
+	command := findPythonEnv(filepath.Dir(path), root).python("-m " + module)
+	if root != "." {
+		command = fmt.Sprintf("cd %s && %s", shellQuote(root), command)
+	}
+	return command, nil
+}
+
+func (pyprojectDetector) Name() Extension { return PyProject }
+
+func (d pyprojectDetector) Match(path string, info fs.FileInfo) bool {
+	return info.Name() == PyProject && len(d.Targets(path)) > 0
+}
+
+// Targets are names of console scripts in alphabetical order
+func (pyprojectDetector) Targets(path string) []string {
+	project, err := readPyProject(path)
+	if err != nil {
+		return nil
+	}
+
+	runners := project.scriptRunners()
+	targets := make([]string, 0, len(runners))
+	for name := range runners {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+func (pyprojectDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+	project, err := readPyProject(path)
+	if err != nil {
+		return "", err
+	}
+
+	runner, ok := project.scriptRunners()[target]
+	if !ok {
+		return "", fmt.Errorf("%w: script %s", ErrCommandNotFound, target)
+	}
+
+	dir := filepath.Dir(path)
+	command := fmt.Sprintf("%s %s %s", runner, shellQuote(target), BashArgs)
+	if runner == "" {
+		command = findPythonEnv(dir, dir).script(target)
+	}
+
+	if dir != "." {
+		command = fmt.Sprintf("cd %s && %s", shellQuote(dir), command)
+	}
+	return command, nil
+}
+
+// scriptRunners maps scripts to tools running them, standard console
+// scripts have no runner as they are installed to the environment
+func (p pyproject) scriptRunners() map[string]string {
+	runners := make(map[string]string)
+	for name := range p.Tool.PDM.Scripts {
+		// _ holds options shared by PDM scripts
+		if !strings.HasPrefix(name, "_") {
+			runners[name] = scriptRunners["pdm"]
+		}
+	}
+	for name := range p.Tool.Poetry.Scripts {
+		runners[name] = scriptRunners["poetry"]
+	}
+	for name := range p.Project.Scripts {
+		runners[name] = ""
+	}
+	return runners
+}
+
+// pythonModule returns the directory python -m is run from, relative to
+// the working directory, and the dotted name of the package in dir. Parent
+// packages are the directories with __init__.py files.
+func pythonModule(dir string) (string, string) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ".", filepath.Base(dir)
+	}
+
+	parts := []string{filepath.Base(absDir)}
+	root := filepath.Dir(absDir)
+	for ; root != filepath.Dir(root); root = filepath.Dir(root) {
+		if _, err := os.Stat(filepath.Join(root, pythonInitFile)); err != nil {
+			break
+		}
+		parts = append([]string{filepath.Base(root)}, parts...)
+	}
+
+	if rel, err := relativeToWd(root); err == nil {
+		root = rel
+	}
+	return root, strings.Join(parts, ".")
+}
+
+// findPythonEnv looks for a virtualenv or a uv project from dir up to the
+// repository root, the virtualenv path is relative to base, the directory the
+// command runs in
+func findPythonEnv(dir, base string) pythonEnv {
+	var env pythonEnv
+	walkUpInRepo(dir, func(dir string) bool {
+		for _, name := range virtualenvDirs {
+			venv := filepath.Join(dir, name)
+			if _, err := os.Stat(filepath.Join(venv, "bin", "python")); err != nil {
+				continue
+			}
+			env.venv = venv
+			if absBase, err := filepath.Abs(base); err == nil {
+				if rel, err := filepath.Rel(absBase, venv); err == nil {
+					env.venv = rel
+				}
+			}
+			return true
+		}
+
+		_, err := os.Stat(filepath.Join(dir, "uv.lock"))
+		env.uv = err == nil
+		return env.uv
+	})
+	return env
+}
+
+// python runs the interpreter of the environment with the arguments
+func (env pythonEnv) python(args string) string {
+	switch {
+	case env.venv != "":
+		return fmt.Sprintf("%s %s %s", filepath.Join(env.venv, "bin", "python"), args, BashArgs)
+	case env.uv:
+		return fmt.Sprintf("uv run python %s %s", args, BashArgs)
+	default:
+		return fmt.Sprintf("python %s %s", args, BashArgs)
+	}
+}
+
+// script runs a console script installed to the environment
+func (env pythonEnv) script(name string) string {
+	switch {
+	case env.venv != "":
+		return fmt.Sprintf("%s %s", filepath.Join(env.venv, "bin", name), BashArgs)
+	case env.uv:
+		return fmt.Sprintf("uv run %s %s", shellQuote(name), BashArgs)
+	default:
+		return fmt.Sprintf("%s %s", shellQuote(name), BashArgs)
+	}
+}
+
+func readPyProject(path string) (pyproject, error) {
+	var project pyproject
+	if _, err := toml.DecodeFile(path, &project); err != nil {
+		return pyproject{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return project, nil
+}
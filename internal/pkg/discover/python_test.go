@@ -0,0 +1,103 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPythonProjects(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[Filename]string
+	}{
+		{
+			name: "console scripts with a virtualenv",
+			files: map[string]string{
+				"pyproject.toml": `
+[project.scripts]
+serve = "app.server:main"
+`,
+				".venv/bin/python":        "",
+				"src/app/__init__.py":     "",
+				"src/app/__main__.py":     "",
+				"src/app/cli/__init__.py": "",
+				"src/app/cli/__main__.py": "",
+				"tools/lint.py":           "if __name__ == '__main__':\n    lint()",
+			},
+			want: map[Filename]string{
+				"pyproject.toml:serve":    ".venv/bin/serve $*",
+				"src/app/__main__.py":     "cd src && ../.venv/bin/python -m app $*",
+				"src/app/cli/__main__.py": "cd src && ../.venv/bin/python -m app.cli $*",
+				"tools/lint.py":           ".venv/bin/python tools/lint.py $*",
+			},
+		},
+		{
+			name: "uv project",
+			files: map[string]string{
+				"pyproject.toml": `
+[project.scripts]
+serve = "app.server:main"
+`,
+				"uv.lock":         "",
+				"app/__main__.py": "",
+			},
+			want: map[Filename]string{
+				"pyproject.toml:serve": "uv run serve $*",
+				"app/__main__.py":      "uv run python -m app $*",
+			},
+		},
+		{
+			name: "poetry and pdm scripts",
+			files: map[string]string{
+				"api/pyproject.toml": `
+[tool.poetry.scripts]
+api = "api.main:run"
+
+[tool.pdm.scripts]
+_.env_file = ".env"
+migrate = "alembic upgrade head"
+`,
+				"main.py": "if __name__ == \"__main__\": main()",
+			},
+			want: map[Filename]string{
+				"api/pyproject.toml:api":     "cd api && poetry run api $*",
+				"api/pyproject.toml:migrate": "cd api && pdm run migrate $*",
+				"main.py":                    "python main.py $*",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeTree(t, tt.files)
+
+			candidates, err := ListCandidates(config.Config{SkipPaths: []string{".venv"}}, Options{})
+			require.NoError(t, err)
+
+			got := make(map[Filename]string)
+			for _, c := range candidates {
+				got[c.Path] = c.Command
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFindPythonEnv(t *testing.T) {
+	writeTree(t, map[string]string{
+		"venv/bin/python":    "",
+		"uv.lock":            "",
+		"app/main.py":        "",
+		"repo/.git/HEAD":     "ref: refs/heads/master\n",
+		"repo/tools/lint.py": "",
+	})
+
+	assert.Equal(t, pythonEnv{venv: "venv"}, findPythonEnv("app", "."))
+	assert.Equal(t, pythonEnv{}, findPythonEnv("repo/tools", "repo"))
+}
@@ -0,0 +1,129 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	baseScore         = 100
+	depthPenalty      = 10
+	entryNameBonus    = 20
+	entryPathBonus    = 30
+	manifestBonus     = 15
+	historyBonus      = 30
+	maxHistoryChoices = 3
+
+	// maxBaseScore is the score of the best placed file never chosen before
+	maxBaseScore = baseScore + entryNameBonus + entryPathBonus + manifestBonus
+)
+
+var (
+	// entryNames are conventional names of entry point files
+	entryNames = map[string]bool{
+		"main.go": true, "main.rs": true, "main.c": true, "main.cpp": true,
+		"main.py": true, "__main__.py": true, "index.js": true,
+	}
+
+	// entryTargets are conventional names of targets starting a project
+	entryTargets = map[string]bool{"start": true, "dev": true, "serve": true, "run": true}
+
+	// manifests are project files lying next to entry points
+	manifests = []string{
+		"go.mod", "Cargo.toml", "package.json", "pyproject.toml", "setup.py",
+		"CMakeLists.txt", "meson.build", "configure.ac", "build.gradle", "build.gradle.kts", "pom.xml",
+		"Gemfile", "composer.json", "mix.exs", "stack.yaml", "build.zig", "deno.json",
+	}
+)
+
+// rankCandidates flattens discovered executables to a list from the most
+// likely entry point to the least likely one, ties are ordered by extension
+// and path, so the order is stable between runs. Candidates are numbered for
+// --pick before past choices move them.
+func rankCandidates(project string, executables map[Extension][]Filename, h history) []Candidate {
+	candidates := make([]Candidate, 0, len(executables))
+	for ext, paths := range executables {
+		for _, path := range paths {
+			c := Candidate{Extension: ext, Path: path}
+			c.Score = scoreCandidate(project, c, history{})
+			candidates = append(candidates, c)
+		}
+	}
+
+	sortCandidates(candidates)
+	for idx := range candidates {
+		candidates[idx].Number = idx + 1
+		candidates[idx].Score += historyScore(project, candidates[idx].Path, h)
+	}
+	sortCandidates(candidates)
+	return candidates
+}
+
+func sortCandidates(candidates []Candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Extension != candidates[j].Extension {
+			return candidates[i].Extension < candidates[j].Extension
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+}
+
+// scoreCandidate prefers shallow files with conventional names next to
+// project manifests and the files the user has chosen before
+func scoreCandidate(project string, c Candidate, h history) int {
+	path, target := splitTarget(c.Path)
+	path = filepath.ToSlash(filepath.Clean(path))
+	dir, name := filepath.Split(path)
+
+	score := baseScore - depthPenalty*strings.Count(path, "/")
+	if entryNames[name] || entryTargets[target] {
+		score += entryNameBonus
+	}
+
+	switch {
+	case path == "cmd/"+filepath.Base(project)+"/main.go", strings.HasSuffix(path, "src/main.rs"):
+		score += entryPathBonus
+	}
+
+	// the manifest of src/main.rs is one level higher
+	manifestDirs := []string{dir}
+	if filepath.Base(dir) == "src" {
+		manifestDirs = append(manifestDirs, filepath.Dir(filepath.Clean(dir)))
+	}
+	if hasManifest(manifestDirs...) {
+		score += manifestBonus
+	}
+
+	return score + historyScore(project, c.Path, h)
+}
+
+// historyScore rewards the choices of the user up to maxHistoryChoices
+func historyScore(project string, path Filename, h history) int {
+	choices := h.count(project, path)
+	if choices > maxHistoryChoices {
+		choices = maxHistoryChoices
+	}
+	return historyBonus * choices
+}
+
+func hasManifest(dirs ...string) bool {
+	for _, dir := range dirs {
+		for _, manifest := range manifests {
+			if _, err := os.Stat(filepath.Join(dir, manifest)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clearWinner reports whether the first candidate outscores the second one
+// by the margin, a non-positive margin disables it
+func clearWinner(candidates []Candidate, margin int) bool {
+	return margin > 0 && len(candidates) > 1 && candidates[0].Score-candidates[1].Score >= margin
+}
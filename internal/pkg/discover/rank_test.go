@@ -0,0 +1,109 @@
+package discover
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankCandidates(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string
+		executables map[Extension][]Filename
+		history     map[Filename]int
+		want        []Filename
+	}{
+		{
+			name: "shallow files first",
+			executables: map[Extension][]Filename{
+				".go": {"tools/gen/gen.go", "main.go"},
+				".sh": {"scripts/run.sh"},
+			},
+			want: []Filename{"main.go", "scripts/run.sh", "tools/gen/gen.go"},
+		},
+		{
+			name: "cmd of the project",
+			executables: map[Extension][]Filename{
+				".go": {"cmd/other/main.go", "cmd/project/main.go"},
+			},
+			want: []Filename{"cmd/project/main.go", "cmd/other/main.go"},
+		},
+		{
+			name:  "manifests",
+			files: []string{"cli/Cargo.toml", "server/go.mod"},
+			executables: map[Extension][]Filename{
+				".go": {"server/main.go", "tools/main.go"},
+				".rs": {"cli/src/main.rs", "bench/src/main.rs"},
+			},
+			want: []Filename{"cli/src/main.rs", "bench/src/main.rs", "server/main.go", "tools/main.go"},
+		},
+		{
+			name: "past choices",
+			executables: map[Extension][]Filename{
+				".go": {"main.go"},
+				".sh": {"scripts/run.sh"},
+			},
+			history: map[Filename]int{"scripts/run.sh": 2},
+			want:    []Filename{"scripts/run.sh", "main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for _, filename := range tt.files {
+				files[path.Join("project", filename)] = ""
+			}
+			rootPath := path.Join(writeTree(t, files), "project")
+			require.NoError(t, os.MkdirAll(rootPath, 0755))
+			require.NoError(t, os.Chdir(rootPath))
+
+			h := history{projectDir(): tt.history}
+
+			var got []Filename
+			for _, c := range rankCandidates(projectDir(), tt.executables, h) {
+				got = append(got, c.Path)
+			}
+			assert.EqualValues(t, tt.want, got)
+		})
+	}
+}
+
+func TestChooseClearWinner(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	const goMain = "package main\n\nfunc main() {}\n"
+	writeTree(t, map[string]string{"main.go": goMain, "go.mod": goMain, "scripts/ci/lint.sh": goMain})
+
+	command, err := ChooseCommand(config.Config{AutoPickMargin: 50}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "go run . $*", command)
+
+	_, err = ChooseCommand(config.Config{}, Options{})
+	require.ErrorIs(t, err, ErrAmbiguousChoice)
+
+	for i := 0; i < 3; i++ {
+		_, err = ChooseCommand(config.Config{}, Options{Pick: "scripts/ci/lint.sh"})
+		require.NoError(t, err)
+	}
+	command, err = ChooseCommand(config.Config{AutoPickMargin: 30}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "go run . $*", command, "picks by the flag are not remembered")
+
+	// choices are remembered and outweigh the conventions
+	for i := 0; i < 3; i++ {
+		require.NoError(t, loadHistory().record(projectDir(), "scripts/ci/lint.sh"))
+	}
+	command, err = ChooseCommand(config.Config{AutoPickMargin: 30}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "bash scripts/ci/lint.sh $*", command)
+
+	command, err = ChooseCommand(config.Config{}, Options{Pick: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "go run . $*", command, "numbers do not depend on the history")
+}
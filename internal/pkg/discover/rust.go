@@ -0,0 +1,316 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const cargoManifestFile = "Cargo.toml"
+
+type (
+	// rustDetector finds binary targets of cargo packages: src/main.rs,
+	// src/bin, examples and [[bin]] or [[example]] entries of Cargo.toml.
+	// Sources out of cargo packages are found by their main function.
+	rustDetector struct{}
+
+	cargoManifest struct {
+		Package *struct {
+			Name         string `toml:"name"`
+			Autobins     *bool  `toml:"autobins"`
+			Autoexamples *bool  `toml:"autoexamples"`
+		} `toml:"package"`
+		Workspace *struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+		Bins     []cargoTarget `toml:"bin"`
+		Examples []cargoTarget `toml:"example"`
+	}
+
+	cargoTarget struct {
+		Name string `toml:"name"`
+		Path string `toml:"path"`
+	}
+
+	// rustTarget is a runnable target of a cargo package
+	rustTarget struct {
+		pkg  string
+		kind string // bin or example
+		name string
+		// dir is the package directory
+		dir string
+		// workspace is the directory cargo resolves the package from
+		workspace string
+	}
+
+	cachedManifest struct {
+		modTime  time.Time
+		manifest cargoManifest
+		err      error
+	}
+)
+
+// cargoManifests keeps parsed manifests, every source file of a package
+// needs its manifest
+// nolint: gochecknoglobals
+var cargoManifests = struct {
+	sync.Mutex
+	byPath map[string]cachedManifest
+}{byPath: make(map[string]cachedManifest)}
+
+func (rustDetector) Name() Extension { return RustExtension }
+
+func (rustDetector) Match(path string, info fs.FileInfo) bool {
+	if filepath.Ext(info.Name()) != RustExtension {
+		return false
+	}
+
+	if _, inPackage, ok := findRustTarget(path); inPackage {
+		return ok
+	}
+	return hasMain(path, RustMainDecl)
+}
+
+func (rustDetector) RunCommand(path string) (string, error) {
+	target, _, ok := findRustTarget(path)
+	if !ok {
+		return "cargo run " + BashArgs, nil
+	}
+
+	selector, err := target.selector()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cargo run %s --%s %s %s", selector, target.kind, target.name, BashArgs), nil
+}
+
+func (rustDetector) BuildCommand(path, _ string) ([]string, error) {
+	target, _, ok := findRustTarget(path)
+	if !ok {
+		return []string{"cargo", "install", "--path", guessCrateRoot(path)}, nil
+	}
+
+	dir, err := relativeToWd(target.dir)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"cargo", "install", "--path", dir, "--" + target.kind, target.name}, nil
+}
+
+// selector chooses the package by its name if cargo finds its workspace
+// from the working directory, or by its manifest path otherwise
+func (t rustTarget) selector() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if rel, err := filepath.Rel(t.workspace, wd); err == nil && !strings.HasPrefix(rel, "..") {
+		return "-p " + t.pkg, nil
+	}
+
+	dir, err := relativeToWd(t.dir)
+	if err != nil {
+		return "", err
+	}
+	return "--manifest-path " + filepath.Join(dir, cargoManifestFile), nil
+}
+
+// findRustTarget returns the target the source file is the entry point of.
+// The second result reports whether the file is in a cargo package at all,
+// a package with a broken manifest has no targets.
+func findRustTarget(path string) (rustTarget, bool, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return rustTarget{}, false, false
+	}
+
+	pkgDir, ok := findUp(filepath.Dir(abs), cargoManifestFile)
+	if !ok {
+		return rustTarget{}, false, false
+	}
+
+	manifest, err := readCargoManifest(filepath.Join(pkgDir, cargoManifestFile))
+	switch {
+	case err != nil:
+		return rustTarget{}, true, false
+	case manifest.Package == nil:
+		// a virtual manifest of a workspace
+		return rustTarget{}, false, false
+	}
+
+	for _, target := range manifest.targets(pkgDir) {
+		if target.path == abs {
+			return rustTarget{
+				pkg:       manifest.Package.Name,
+				kind:      target.kind,
+				name:      target.Name,
+				dir:       pkgDir,
+				workspace: cargoWorkspace(pkgDir, manifest),
+			}, true, true
+		}
+	}
+	return rustTarget{}, true, false
+}
+
+type resolvedTarget struct {
+	cargoTarget
+	kind string
+	path string
+}
+
+// targets lists binaries and examples of the package like cargo does:
+// explicit entries first, then the automatically discovered ones
+func (m cargoManifest) targets(dir string) []resolvedTarget {
+	var targets []resolvedTarget
+	explicit := make(map[string]bool)
+
+	add := func(kind string, target cargoTarget) {
+		path := filepath.Join(dir, filepath.FromSlash(target.Path))
+		if explicit[path] {
+			return
+		}
+		explicit[path] = true
+		targets = append(targets, resolvedTarget{target, kind, path})
+	}
+
+	for _, bin := range m.Bins {
+		if bin.Path == "" {
+			bin.Path = defaultTargetPath(dir, "src/bin", bin.Name)
+			if bin.Name == m.Package.Name {
+				bin.Path = "src/main.rs"
+			}
+		}
+		add("bin", bin)
+	}
+	for _, example := range m.Examples {
+		if example.Path == "" {
+			example.Path = defaultTargetPath(dir, "examples", example.Name)
+		}
+		add("example", example)
+	}
+
+	if m.Package.Autobins == nil || *m.Package.Autobins {
+		if _, err := os.Stat(filepath.Join(dir, "src", "main.rs")); err == nil {
+			add("bin", cargoTarget{Name: m.Package.Name, Path: "src/main.rs"})
+		}
+		for _, target := range discoverTargets(dir, "src/bin") {
+			add("bin", target)
+		}
+	}
+	if m.Package.Autoexamples == nil || *m.Package.Autoexamples {
+		for _, target := range discoverTargets(dir, "examples") {
+			add("example", target)
+		}
+	}
+	return targets
+}
+
+// discoverTargets finds <name>.rs and <name>/main.rs files in the directory
+func discoverTargets(dir, targetsDir string) []cargoTarget {
+	entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(targetsDir)))
+	if err != nil {
+		return nil
+	}
+
+	var targets []cargoTarget
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case !entry.IsDir() && filepath.Ext(name) == RustExtension:
+			targets = append(targets, cargoTarget{
+				Name: strings.TrimSuffix(name, RustExtension),
+				Path: targetsDir + "/" + name,
+			})
+		case entry.IsDir():
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(targetsDir), name, "main.rs")); err == nil {
+				targets = append(targets, cargoTarget{Name: name, Path: targetsDir + "/" + name + "/main.rs"})
+			}
+		}
+	}
+	return targets
+}
+
+func defaultTargetPath(dir, targetsDir, name string) string {
+	nested := targetsDir + "/" + name + "/main.rs"
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(nested))); err == nil {
+		return nested
+	}
+	return targetsDir + "/" + name + RustExtension
+}
+
+// cargoWorkspace returns the root of the workspace the package is a member
+// of, or the package directory if it is not in a workspace
+func cargoWorkspace(pkgDir string, manifest cargoManifest) string {
+	if manifest.Workspace != nil {
+		return pkgDir
+	}
+
+	root, ok := findUp(filepath.Dir(pkgDir), cargoManifestFile)
+	for ; ok; root, ok = findUp(filepath.Dir(root), cargoManifestFile) {
+		ws, err := readCargoManifest(filepath.Join(root, cargoManifestFile))
+		if err != nil || ws.Workspace == nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, pkgDir)
+		if err != nil {
+			return pkgDir
+		}
+		rel = filepath.ToSlash(rel)
+		if matchAny(ws.Workspace.Members, rel) && !matchAny(ws.Workspace.Exclude, rel) {
+			return root
+		}
+		return pkgDir
+	}
+	return pkgDir
+}
+
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(strings.TrimSuffix(pattern, "/"), path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func readCargoManifest(path string) (cargoManifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return cargoManifest{}, err
+	}
+
+	cargoManifests.Lock()
+	defer cargoManifests.Unlock()
+
+	if cached, ok := cargoManifests.byPath[path]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.manifest, cached.err
+	}
+
+	var manifest cargoManifest
+	if _, err = toml.DecodeFile(path, &manifest); err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	cargoManifests.byPath[path] = cachedManifest{info.ModTime(), manifest, err}
+	return manifest, err
+}
+
+// guessCrateRoot is the directory above src for sources out of packages
+func guessCrateRoot(path string) string {
+	pathParts := strings.Split(path, string(os.PathSeparator))
+	for idx := range pathParts {
+		if pathParts[idx] == "src" {
+			return filepath.Join(pathParts[:idx]...)
+		}
+	}
+	return "."
+}
@@ -0,0 +1,107 @@
+package discover
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCargoWorkspace(t *testing.T) {
+	files := map[string]string{
+		"Cargo.toml": `
+[workspace]
+members = ["crates/*"]
+exclude = ["crates/legacy"]
+`,
+		"crates/cli/Cargo.toml": `
+[package]
+name = "cli"
+
+[[bin]]
+name = "admin"
+path = "tools/admin.rs"
+
+[[example]]
+name = "tour"
+`,
+		"crates/cli/src/main.rs":           "fn main() {}",
+		"crates/cli/src/lib.rs":            "pub fn run() {}",
+		"crates/cli/src/bin/worker.rs":     "fn main() {}",
+		"crates/cli/src/bin/sync/main.rs":  "fn main() {}",
+		"crates/cli/src/bin/sync/util.rs":  "fn main() {}",
+		"crates/cli/tools/admin.rs":        "fn main() {}",
+		"crates/cli/examples/tour/main.rs": "fn main() {}",
+		"crates/cli/build.rs":              "fn main() {}",
+		"crates/legacy/Cargo.toml": `
+[package]
+name = "legacy"
+autobins = false
+
+[[bin]]
+name = "legacy"
+`,
+		"crates/legacy/src/main.rs":       "fn main() {}",
+		"crates/legacy/src/bin/unused.rs": "fn main() {}",
+	}
+	writeTree(t, files)
+
+	executables, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+
+	got := executables[RustExtension]
+	sort.Strings(got)
+	assert.Equal(t, []Filename{
+		"crates/cli/examples/tour/main.rs",
+		"crates/cli/src/bin/sync/main.rs",
+		"crates/cli/src/bin/worker.rs",
+		"crates/cli/src/main.rs",
+		"crates/cli/tools/admin.rs",
+		"crates/legacy/src/main.rs",
+	}, got)
+
+	tests := []struct {
+		path      string
+		wantRun   string
+		wantBuild []string
+	}{
+		{
+			path:      "crates/cli/src/main.rs",
+			wantRun:   "cargo run -p cli --bin cli $*",
+			wantBuild: []string{"cargo", "install", "--path", "crates/cli", "--bin", "cli"},
+		},
+		{
+			path:      "crates/cli/src/bin/sync/main.rs",
+			wantRun:   "cargo run -p cli --bin sync $*",
+			wantBuild: []string{"cargo", "install", "--path", "crates/cli", "--bin", "sync"},
+		},
+		{
+			path:      "crates/cli/tools/admin.rs",
+			wantRun:   "cargo run -p cli --bin admin $*",
+			wantBuild: []string{"cargo", "install", "--path", "crates/cli", "--bin", "admin"},
+		},
+		{
+			path:      "crates/cli/examples/tour/main.rs",
+			wantRun:   "cargo run -p cli --example tour $*",
+			wantBuild: []string{"cargo", "install", "--path", "crates/cli", "--example", "tour"},
+		},
+		{
+			path:      "crates/legacy/src/main.rs",
+			wantRun:   "cargo run --manifest-path crates/legacy/Cargo.toml --bin legacy $*",
+			wantBuild: []string{"cargo", "install", "--path", "crates/legacy", "--bin", "legacy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			run, err := rustDetector{}.RunCommand(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+
+			build, err := rustDetector{}.BuildCommand(tt.path, "app")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBuild, build)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package discover
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ShebangName groups scripts found by their #! lines or executable bits
+	ShebangName = "shebang"
+
+	// maxScriptSize skips data files and bundles that are not hand-written
+	// entry points
+	maxScriptSize = 1 << 20
+	// headerSize is the prefix of a file read to tell a script from a binary
+	headerSize = 512
+)
+
+// nolint: gochecknoglobals
+var elfMagic = []byte("\x7fELF")
+
+// shebangDetector finds scripts of any language by their #! lines and
+// extensionless executables, compiled binaries are skipped. It is matched
+// after the detectors of known languages, so it gets the files they do not
+// launch, such as executable Python files without a main guard.
+type shebangDetector struct{ runOnly }
+
+func (shebangDetector) Name() Extension { return ShebangName }
+
+func (shebangDetector) Match(path string, info fs.FileInfo) bool {
+	if !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > maxScriptSize {
+		return false
+	}
+
+	header, ok := readHeader(path)
+	if !ok || bytes.HasPrefix(header, elfMagic) || bytes.IndexByte(header, 0) >= 0 {
+		return false
+	}
+
+	if bytes.HasPrefix(header, []byte("#!")) {
+		return true
+	}
+	return isExecutable(info) && filepath.Ext(info.Name()) == ""
+}
+
+// RunCommand runs executables directly and other scripts by the
+// interpreter of their #! lines
+func (shebangDetector) RunCommand(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	header, _ := readHeader(path)
+	interpreter := shebangInterpreter(header)
+	if isExecutable(info) || interpreter == "" {
+		return shellQuote(packagePath(path)) + " " + BashArgs, nil
+	}
+	return interpreter + " " + shellQuote(path) + " " + BashArgs, nil
+}
+
+// shebangInterpreter returns the command of the #! line, env is dropped
+// as the interpreter is looked up in PATH anyway
+func shebangInterpreter(header []byte) string {
+	if !bytes.HasPrefix(header, []byte("#!")) {
+		return ""
+	}
+
+	line := string(header[2:])
+	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) > 0 && filepath.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "-S" {
+			fields = fields[1:]
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+func readHeader(path string) ([]byte, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, false
+	}
+	return header[:n], true
+}
+
+func isExecutable(info fs.FileInfo) bool {
+	return info.Mode().Perm()&0111 != 0
+}
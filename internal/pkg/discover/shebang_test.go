@@ -0,0 +1,54 @@
+package discover
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShebangDetector(t *testing.T) {
+	writeTree(t, map[string]string{
+		"bin/serve":     "#!/bin/sh\nexec python -m http.server\n",
+		"bin/setup":     "set -e\nnpm ci\n",
+		"bin/app":       "\x7fELF\x02\x01\x01",
+		"bin/data":      "#!" + strings.Repeat("x", maxScriptSize),
+		"tools/gen.tcl": "#!/usr/bin/env tclsh\nputs 1\n",
+		"tools/run.awk": "#!/usr/bin/env -S awk -f\nBEGIN { print 1 }\n",
+		"tools/notes":   "not a script",
+		"tools/lib.py":  "#!/usr/bin/env python\nimport os\n",
+		"my tools/run":  "#!/bin/sh\necho\n",
+	})
+	for _, filename := range []string{"tools/gen.tcl", "tools/notes"} {
+		require.NoError(t, os.Chmod(filename, 0644))
+	}
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		ShebangName: {"bin/serve", "bin/setup", "my tools/run", "tools/gen.tcl", "tools/lib.py", "tools/run.awk"},
+	}, got)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "bin/serve", want: "./bin/serve $*"},
+		{path: "bin/setup", want: "./bin/setup $*"},
+		{path: "tools/gen.tcl", want: "tclsh tools/gen.tcl $*"},
+		{path: "tools/run.awk", want: "./tools/run.awk $*"},
+		{path: "tools/lib.py", want: "./tools/lib.py $*"},
+		{path: "my tools/run", want: "'./my tools/run' $*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := prepareCommand(config.Config{}, ShebangName, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, "awk -f", shebangInterpreter([]byte("#!/usr/bin/env -S awk -f\n")))
+}
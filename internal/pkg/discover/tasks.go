@@ -0,0 +1,300 @@
+package discover
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	JustRecipeDecl = regexp.MustCompile(`^@?([A-Za-z_][\w-]*)[^:]*:([^=]|$)`)
+	JustDocDecl    = regexp.MustCompile(`^\[.*\bdoc\(\s*["'](.*)["']\s*\).*\]$`)
+	// JustPrivateDecl is the private attribute among other attributes, not
+	// the word in an argument of one
+	JustPrivateDecl = regexp.MustCompile(`^\[\s*(?:[\w-]+(?:\([^)]*\))?\s*,\s*)*private\s*[,\]]`)
+)
+
+type (
+	// makeDetector offers targets of makefiles, file targets are skipped
+	// unless they are phony
+	makeDetector struct{ name string }
+
+	// justDetector offers public recipes of justfiles, launcher arguments
+	// are passed as recipe parameters
+	justDetector struct{ runOnly }
+
+	// taskDetector offers public tasks of Taskfiles, launcher arguments are
+	// passed as CLI_ARGS
+	taskDetector struct{ runOnly }
+
+	// task is a target of a task runner file
+	task struct {
+		name        string
+		description string
+	}
+
+	taskfileTask struct {
+		Desc     string `yaml:"desc"`
+		Internal bool   `yaml:"internal"`
+	}
+)
+
+// nolint: gochecknoglobals
+var (
+	justfiles = map[Filename]bool{"justfile": true, "Justfile": true, ".justfile": true}
+	taskfiles = map[Filename]bool{
+		"Taskfile.yml": true, "Taskfile.yaml": true, "taskfile.yml": true, "taskfile.yaml": true,
+		"Taskfile.dist.yml": true, "Taskfile.dist.yaml": true,
+	}
+
+	// justKeywords start lines of justfiles that are not recipes
+	justKeywords = map[string]bool{"alias": true, "set": true, "export": true, "import": true, "mod": true}
+)
+
+func (d makeDetector) Name() Extension { return d.name }
+
+func (d makeDetector) Match(path string, info fs.FileInfo) bool {
+	if info.Name() != d.name && filepath.Ext(info.Name()) != d.name {
+		return false
+	}
+	return len(d.Targets(path)) > 0
+}
+
+func (makeDetector) Targets(path string) []string {
+	return taskNames(parseMakefile(path))
+}
+
+func (makeDetector) Describe(path string) string {
+	path, target := splitTarget(path)
+	return taskDescription(parseMakefile(path), target)
+}
+
+func (makeDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+	if target == "" {
+		return makeCommand(path, BashArgs), nil
+	}
+	return makeCommand(path, shellQuote(target)+" "+BashArgs), nil
+}
+
+// BuildCommand installs by the install target, other targets do not build
+func (makeDetector) BuildCommand(path, _ string) ([]string, error) {
+	path, target := splitTarget(path)
+	if target != "" && target != "install" {
+		return nil, ErrCommandNotFound
+	}
+	return append(makeArgs(path), "install"), nil
+}
+
+// makeCommand runs make for the makefile with shell words args
+func makeCommand(path, args string) string {
+	words := makeArgs(path)
+	for idx := range words {
+		words[idx] = shellQuote(words[idx])
+	}
+	return strings.Join(append(words, args), " ")
+}
+
+// makeArgs runs make for the makefile, Makefile in other directories are
+// run with -C and other makefiles with -f
+func makeArgs(path string) []string {
+	switch {
+	case filepath.Base(path) != Makefile:
+		return []string{"make", "-f", path}
+	case filepath.Dir(path) != ".":
+		return []string{"make", "-C", filepath.Dir(path)}
+	default:
+		return []string{"make"}
+	}
+}
+
+// parseMakefile finds rule targets with descriptions from comments above
+// them or from ## comments after their prerequisites
+func parseMakefile(path string) []task {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		tasks   []task
+		comment string
+		phony   = make(map[string]bool)
+		seen    = make(map[string]bool)
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		case strings.HasPrefix(line, ".PHONY:"):
+			for _, name := range strings.Fields(strings.TrimPrefix(line, ".PHONY:")) {
+				phony[name] = true
+			}
+		}
+
+		idx := strings.Index(line, ":")
+		if idx <= 0 || strings.ContainsAny(line[:1], " \t") || strings.Contains(line[:idx], "=") ||
+			strings.HasPrefix(line[idx:], ":=") {
+			comment = ""
+			continue
+		}
+
+		description := comment
+		if inline := strings.Index(line[idx:], "##"); inline >= 0 {
+			description = strings.TrimSpace(line[idx+inline+2:])
+		}
+
+		for _, name := range strings.Fields(line[:idx]) {
+			if seen[name] || strings.HasPrefix(name, ".") || strings.ContainsAny(name, "%$()") {
+				continue
+			}
+			seen[name] = true
+			tasks = append(tasks, task{name: name, description: description})
+		}
+		comment = ""
+	}
+
+	// file targets are build steps rather than entry points
+	targets := tasks[:0]
+	for _, t := range tasks {
+		if phony[t.name] || !strings.ContainsAny(t.name, "./") {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+func (justDetector) Name() Extension { return "justfile" }
+
+func (d justDetector) Match(path string, info fs.FileInfo) bool {
+	return justfiles[info.Name()] && len(d.Targets(path)) > 0
+}
+
+func (justDetector) Targets(path string) []string {
+	return taskNames(parseJustfile(path))
+}
+
+func (justDetector) Describe(path string) string {
+	path, target := splitTarget(path)
+	return taskDescription(parseJustfile(path), target)
+}
+
+func (justDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+	return inDir(filepath.Dir(path), fmt.Sprintf("just %s %s", shellQuote(target), BashArgs)), nil
+}
+
+// parseJustfile finds public recipes with descriptions from doc attributes
+// or comments above them
+func parseJustfile(path string) []task {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		tasks   []task
+		comment string
+		private bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			continue
+		case strings.HasPrefix(line, "["):
+			if m := JustDocDecl.FindStringSubmatch(line); len(m) > 0 {
+				comment = m[1]
+			}
+			private = private || JustPrivateDecl.MatchString(line)
+			continue
+		}
+
+		m := JustRecipeDecl.FindStringSubmatch(line)
+		if len(m) > 0 && !justKeywords[m[1]] && !private && !strings.HasPrefix(m[1], "_") {
+			tasks = append(tasks, task{name: m[1], description: comment})
+		}
+		comment, private = "", false
+	}
+	return tasks
+}
+
+func (taskDetector) Name() Extension { return "Taskfile.yml" }
+
+func (d taskDetector) Match(path string, info fs.FileInfo) bool {
+	return taskfiles[info.Name()] && len(d.Targets(path)) > 0
+}
+
+func (taskDetector) Targets(path string) []string {
+	return taskNames(parseTaskfile(path))
+}
+
+func (taskDetector) Describe(path string) string {
+	path, target := splitTarget(path)
+	return taskDescription(parseTaskfile(path), target)
+}
+
+func (taskDetector) RunCommand(path string) (string, error) {
+	path, target := splitTarget(path)
+	return inDir(filepath.Dir(path), fmt.Sprintf("task %s -- %s", shellQuote(target), BashArgs)), nil
+}
+
+// parseTaskfile finds public tasks in the order they are declared
+func parseTaskfile(path string) []task {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var taskfile struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(content, &taskfile); err != nil {
+		return nil
+	}
+
+	var tasks []task
+	nodes := taskfile.Tasks.Content
+	for idx := 0; idx+1 < len(nodes); idx += 2 {
+		// tasks may be a single command or a list of commands too
+		var t taskfileTask
+		if nodes[idx+1].Kind == yaml.MappingNode {
+			_ = nodes[idx+1].Decode(&t)
+		}
+		if !t.Internal {
+			tasks = append(tasks, task{name: nodes[idx].Value, description: t.Desc})
+		}
+	}
+	return tasks
+}
+
+func taskNames(tasks []task) []string {
+	names := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func taskDescription(tasks []task, name string) string {
+	for _, t := range tasks {
+		if t.name == name {
+			return t.description
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskRunners(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	writeTree(t, map[string]string{
+		Makefile: `
+VERSION := 1.0
+BIN = bin/app
+
+.PHONY: build test dist/app
+
+# Compile the application
+build: $(BIN)
+
+test: build ## Run unit tests
+	go test ./...
+
+bin/app: main.c
+	cc -o $@ $<
+
+dist/app:
+
+%.o: %.c
+.DEFAULT_GOAL := build
+`,
+		"tools/release.mk":       "publish:\n\techo\n",
+		"release tools/Makefile": "install:\n\tcp app /usr/local/bin\n",
+		"web/justfile": `
+set shell := ["bash", "-c"]
+alias s := serve
+
+# Start the dev server
+serve port="8080":
+    npm run dev -- --port {{port}}
+
+[private]
+setup:
+    npm ci
+
+[doc('Deploy to an environment')]
+deploy env *flags: setup
+    ./deploy.sh {{env}} {{flags}}
+
+[no-cd, private]
+lint:
+    echo
+
+[doc('Reset private data')]
+reset:
+    rm -rf data
+
+_helper:
+    echo
+`,
+		"api/Taskfile.yml": `
+version: '3'
+tasks:
+  run:
+    desc: Run the API
+    cmds:
+      - go run . {{.CLI_ARGS}}
+  lint: golangci-lint run
+  generate:
+    internal: true
+    cmds:
+      - go generate ./...
+`,
+	})
+
+	got, err := getExecutables(".", config.Config{})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		Makefile:       {"Makefile:build", "Makefile:test", "Makefile:dist/app", "release tools/Makefile:install"},
+		MakeExtension:  {"tools/release.mk:publish"},
+		"justfile":     {"web/justfile:serve", "web/justfile:deploy", "web/justfile:reset"},
+		"Taskfile.yml": {"api/Taskfile.yml:run", "api/Taskfile.yml:lint"},
+	}, got)
+
+	tests := []struct {
+		path            string
+		ext             string
+		wantRun         string
+		wantDescription string
+	}{
+		{
+			path:            "Makefile:build",
+			ext:             Makefile,
+			wantRun:         "make build $*",
+			wantDescription: "Compile the application",
+		},
+		{
+			path:            "Makefile:test",
+			ext:             Makefile,
+			wantRun:         "make test $*",
+			wantDescription: "Run unit tests",
+		},
+		{
+			path:    "tools/release.mk:publish",
+			ext:     MakeExtension,
+			wantRun: "make -f tools/release.mk publish $*",
+		},
+		{
+			path:            "web/justfile:serve",
+			ext:             "justfile",
+			wantRun:         "cd web && just serve $*",
+			wantDescription: "Start the dev server",
+		},
+		{
+			path:            "web/justfile:deploy",
+			ext:             "justfile",
+			wantRun:         "cd web && just deploy $*",
+			wantDescription: "Deploy to an environment",
+		},
+		{
+			path:            "web/justfile:reset",
+			ext:             "justfile",
+			wantRun:         "cd web && just reset $*",
+			wantDescription: "Reset private data",
+		},
+		{
+			path:    "release tools/Makefile:install",
+			ext:     Makefile,
+			wantRun: "make -C 'release tools' install $*",
+		},
+		{
+			path:            "api/Taskfile.yml:run",
+			ext:             "Taskfile.yml",
+			wantRun:         "cd api && task run -- $*",
+			wantDescription: "Run the API",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			run, err := prepareCommand(config.Config{}, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+
+			candidates := []Candidate{{Extension: tt.ext, Path: tt.path}}
+			describeCandidates(config.Config{}, candidates)
+			assert.Equal(t, tt.wantDescription, candidates[0].Description)
+		})
+	}
+
+	_, err = prepareBuildCommand(config.Config{}, Makefile, "Makefile:test", "app")
+	require.ErrorIs(t, err, ErrCommandNotFound)
+
+	build, err := prepareBuildCommand(config.Config{}, Makefile, "release tools/Makefile:install", "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"make", "-C", "release tools", "install"}, build)
+}
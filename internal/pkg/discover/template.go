@@ -0,0 +1,110 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+type (
+	// templateDetector is a detector declared in the config file
+	templateDetector struct {
+		name     string
+		glob     string
+		content  *regexp.Regexp
+		commands config.Commands
+	}
+
+	// overriddenDetector replaces command templates of a built-in detector
+	overriddenDetector struct {
+		Detector
+		commands config.Commands
+	}
+)
+
+func newTemplateDetector(cfg config.Detector) (templateDetector, error) {
+	if _, err := filepath.Match(cfg.Glob, ""); err != nil {
+		return templateDetector{}, fmt.Errorf("detector %q: bad glob: %w", cfg.Name, err)
+	}
+
+	d := templateDetector{name: cfg.Name, glob: cfg.Glob, commands: cfg.Commands}
+	if cfg.Content != "" {
+		content, err := regexp.Compile(cfg.Content)
+		if err != nil {
+			return templateDetector{}, fmt.Errorf("detector %q: bad content regexp: %w", cfg.Name, err)
+		}
+		d.content = content
+	}
+	return d, nil
+}
+
+func (d templateDetector) Name() Extension { return d.name }
+
+func (d templateDetector) Match(path string, info fs.FileInfo) bool {
+	if ok, _ := filepath.Match(d.glob, info.Name()); !ok {
+		return false
+	}
+	return d.content == nil || hasMain(path, d.content)
+}
+
+func (d templateDetector) RunCommand(path string) (string, error) {
+	return runTemplate(d.commands.Run, path)
+}
+
+func (d templateDetector) BuildCommand(path, name string) ([]string, error) {
+	return buildTemplate(d.commands.Build, path, name)
+}
+
+func (d overriddenDetector) RunCommand(path string) (string, error) {
+	if d.commands.Run == "" {
+		return d.Detector.RunCommand(path)
+	}
+	return runTemplate(d.commands.Run, path)
+}
+
+func (d overriddenDetector) BuildCommand(path, name string) ([]string, error) {
+	if d.commands.Build == "" {
+		return d.Detector.BuildCommand(path, name)
+	}
+	return buildTemplate(d.commands.Build, path, name)
+}
+
+func runTemplate(template, path string) (string, error) {
+	if template == "" {
+		return "", ErrCommandNotFound
+	}
+
+	var name string
+	if strings.Contains(template, "{name}") {
+		dir, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		name = filepath.Base(dir)
+	}
+	return expandTemplate(template, path, name), nil
+}
+
+// buildTemplate runs the template with bash, so it may chain commands
+func buildTemplate(template, path, name string) ([]string, error) {
+	if template == "" {
+		return nil, ErrCommandNotFound
+	}
+	return []string{"bash", "-c", expandTemplate(template, path, name)}, nil
+}
+
+func expandTemplate(template, path, name string) string {
+	path, target := splitTarget(path)
+	return strings.NewReplacer(
+		"{path}", path,
+		"{target}", target,
+		"{dir}", filepath.Dir(path),
+		"{name}", name,
+		"{args}", BashArgs,
+	).Replace(template)
+}
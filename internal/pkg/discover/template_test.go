@@ -0,0 +1,102 @@
+package discover
+
+import (
+	"path"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateDetectors(t *testing.T) {
+	cfg := config.Config{
+		Detectors: []config.Detector{
+			{
+				Name:     "typescript",
+				Glob:     "*.ts",
+				Content:  `main\(\)`,
+				Commands: config.Commands{Run: "tsx {path} {args}"},
+			},
+			{
+				Name:     "zig",
+				Glob:     "build.zig",
+				Commands: config.Commands{Run: "cd {dir} && zig build run -- {args}", Build: "zig build && cp zig-out/bin/{name} {name}"},
+			},
+		},
+		Commands: map[string]config.Commands{
+			CPPExtension: {Run: "g++ -O2 -std=c++20 -o main {dir}/*.cpp && ./main {args}"},
+		},
+	}
+
+	rootPath := writeTree(t, map[string]string{
+		"index.ts":  "main()",
+		"types.ts":  "export type A = string",
+		"build.zig": "",
+		"main.cpp":  "int main() {}",
+	})
+
+	got, err := getExecutables(rootPath, cfg)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{
+		"typescript": {path.Join(rootPath, "index.ts")},
+		"zig":        {path.Join(rootPath, "build.zig")},
+		CPPExtension: {path.Join(rootPath, "main.cpp")},
+	}, got)
+
+	tests := []struct {
+		name string
+		ext  string
+		path string
+		want string
+	}{
+		{
+			name: "user detector",
+			ext:  "typescript",
+			path: "src/index.ts",
+			want: "tsx src/index.ts $*",
+		},
+		{
+			name: "user detector dir",
+			ext:  "zig",
+			path: "app/build.zig",
+			want: "cd app && zig build run -- $*",
+		},
+		{
+			name: "overridden built-in",
+			ext:  CPPExtension,
+			path: "src/main.cpp",
+			want: "g++ -O2 -std=c++20 -o main src/*.cpp && ./main $*",
+		},
+		{
+			name: "not overridden built-in",
+			ext:  CExtension,
+			path: "main.c",
+			want: "gcc -O2 -o main *.c && ./main $*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prepareCommand(cfg, tt.ext, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	build, err := prepareBuildCommand(cfg, "zig", "build.zig", "app")
+	require.NoError(t, err)
+	assert.EqualValues(t, []string{"bash", "-c", "zig build && cp zig-out/bin/app app"}, build)
+
+	_, err = prepareBuildCommand(cfg, "typescript", "index.ts", "app")
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+}
+
+func TestTemplateDetectorErrors(t *testing.T) {
+	for _, detector := range []config.Detector{
+		{Name: "bad glob", Glob: "[*.ts"},
+		{Name: "bad content", Glob: "*.ts", Content: "main("},
+	} {
+		_, err := newRegistry(config.Config{Detectors: []config.Detector{detector}})
+		assert.Error(t, err, detector.Name)
+	}
+}
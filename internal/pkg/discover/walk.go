@@ -0,0 +1,404 @@
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+)
+
+// maxSniffSize is the prefix of a file searched for main declarations
+const maxSniffSize = 256 << 10
+
+var (
+	// errStopWalk ends the walk once a confident entry point is found
+	errStopWalk = errors.New("confident entry point found")
+	// errSkipped is reported for paths skipped on purpose, other reported
+	// errors are paths that could not be read
+	errSkipped = errors.New("skipped")
+)
+
+type (
+	// walkOptions change how far the discovery walk goes
+	walkOptions struct {
+		// stop ends the walk when it reports a found candidate as the
+		// confident entry point, candidates found so far are returned
+		stop func(ext Extension, path Filename) bool
+		// cache gives results of files unchanged since the last walk, it
+		// is replaced by the results of this walk if the walk completes
+		cache *discoveryCache
+		// report collects paths the walk skipped and why, the walk goes on
+		report *walkReport
+	}
+
+	// walkReport is safe to add to from the walker and the workers, a nil
+	// report drops everything
+	walkReport struct {
+		mu      sync.Mutex
+		skipped []skippedPath
+	}
+
+	skippedPath struct {
+		path string
+		err  error
+	}
+
+	// walkJob is a file the workers match against detectors, seq keeps the
+	// walk order of results
+	walkJob struct {
+		seq   int
+		path  string
+		entry fs.DirEntry
+		// cached is set if the directory of the file has not changed
+		cached bool
+	}
+
+	walkResult struct {
+		seq     int
+		path    string
+		file    cachedFile
+		matched bool
+	}
+)
+
+// walkExecutables walks the tree in a single goroutine, which decides what
+// paths to visit, while a pool of workers reads files to match them. The
+// result is in the walk order whatever the workers finish first. Paths that
+// cannot be read are reported and skipped, only an unreadable root fails.
+func walkExecutables(root string, cfg config.Config, opts walkOptions) (map[Extension][]Filename, error) {
+	reg, err := newRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		jobs    = make(chan walkJob)
+		results = make(chan walkResult)
+		workers sync.WaitGroup
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				if result, ok := matchFile(reg, job, cfg.MaxFileSize, opts); ok {
+					results <- result
+				}
+			}
+		}()
+	}
+
+	var (
+		found     []walkResult
+		files     = make(map[string]cachedFile)
+		collected = make(chan struct{})
+		w         = &walker{
+			root:    root,
+			cfg:     cfg,
+			opts:    opts,
+			filter:  newPathFilter(root, cfg),
+			jobs:    jobs,
+			dirs:    make(map[string]cachedDir),
+			changed: make(map[string]bool),
+		}
+	)
+	go func() {
+		defer close(collected)
+		for result := range results {
+			files[result.path] = result.file
+			if !result.matched {
+				continue
+			}
+
+			found = append(found, result)
+			if opts.stop != nil && confident(opts.stop, result) {
+				atomic.StoreInt32(&w.stopped, 1)
+			}
+		}
+	}()
+
+	err = w.walk(root, root)
+
+	close(jobs)
+	workers.Wait()
+	close(results)
+	<-collected
+
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+	if err == nil && opts.cache != nil {
+		opts.cache.Dirs, opts.cache.Files = w.dirs, files
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].seq < found[j].seq })
+	executables := make(map[Extension][]Filename)
+	for _, result := range found {
+		executables[result.file.Extension] = append(executables[result.file.Extension], result.file.Paths...)
+	}
+	return executables, nil
+}
+
+// walker decides what paths of the tree are matched, it is used by the
+// walking goroutine only
+type walker struct {
+	root    string
+	cfg     config.Config
+	opts    walkOptions
+	filter  *pathFilter
+	jobs    chan<- walkJob
+	seq     int
+	dirs    map[string]cachedDir
+	changed map[string]bool
+	// walked are the real paths of the walked trees, links into them would
+	// walk the same files again or loop forever
+	walked []string
+	// stopped is set by the collector once a confident entry point is found
+	stopped int32
+}
+
+// walk visits the tree of the real directory dir under the path it has in
+// the search tree, which differs from dir for followed symlinks
+func (w *walker) walk(path, dir string) error {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if abs, err := filepath.Abs(real); err == nil {
+			w.walked = append(w.walked, abs)
+		}
+	}
+
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if path != dir {
+			rel, relErr := filepath.Rel(dir, p)
+			if relErr != nil {
+				return relErr
+			}
+			p = filepath.Join(path, rel)
+		}
+		return w.visit(p, entry, err)
+	})
+}
+
+func (w *walker) visit(path string, entry fs.DirEntry, err error) error {
+	if err != nil {
+		if path == w.root && entry == nil {
+			return err
+		}
+		w.opts.report.add(path, err)
+		if entry != nil && entry.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+	if atomic.LoadInt32(&w.stopped) == 1 {
+		return errStopWalk
+	}
+
+	if w.filter.skipped(path, entry.IsDir()) {
+		w.opts.report.add(path, fmt.Errorf("%w by skip_paths or ignore files", errSkipped))
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
+	if entry.Type()&fs.ModeSymlink != 0 {
+		return w.visitSymlink(path, entry)
+	}
+
+	if entry.IsDir() {
+		if w.cfg.MaxDepth > 0 && depth(w.root, path) >= w.cfg.MaxDepth {
+			w.opts.report.add(path, fmt.Errorf("%w deeper than max_depth", errSkipped))
+			return filepath.SkipDir
+		}
+		w.filter.enter(path)
+		if w.opts.cache != nil {
+			enterCached(w.opts.cache, path, entry, w.dirs, w.changed)
+		}
+		return nil
+	}
+
+	if w.opts.cache != nil && isManifest(entry.Name()) {
+		recordManifest(path, entry, w.dirs)
+	}
+
+	if !w.filter.included(path) {
+		return nil
+	}
+
+	cached := w.opts.cache != nil && !w.changed[filepath.Dir(path)]
+	w.jobs <- walkJob{seq: w.seq, path: path, entry: entry, cached: cached}
+	w.seq++
+	return nil
+}
+
+// visitSymlink matches links to files as the files. Linked directories are
+// walked if FollowSymlinks is set and they are not in a walked tree.
+func (w *walker) visitSymlink(path string, entry fs.DirEntry) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		w.opts.report.add(path, err)
+		return nil
+	}
+
+	if !info.IsDir() {
+		if !w.filter.included(path) {
+			return nil
+		}
+		cached := w.opts.cache != nil && !w.changed[filepath.Dir(path)]
+		w.jobs <- walkJob{seq: w.seq, path: path, entry: fs.FileInfoToDirEntry(info), cached: cached}
+		w.seq++
+		return nil
+	}
+
+	if !w.cfg.FollowSymlinks {
+		w.opts.report.add(path, fmt.Errorf("%w symlinked directory, follow_symlinks is off", errSkipped))
+		return nil
+	}
+	if w.cfg.MaxDepth > 0 && depth(w.root, path) >= w.cfg.MaxDepth {
+		w.opts.report.add(path, fmt.Errorf("%w deeper than max_depth", errSkipped))
+		return nil
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		target, err = filepath.Abs(target)
+	}
+	if err != nil {
+		w.opts.report.add(path, err)
+		return nil
+	}
+	if w.inWalked(target) {
+		w.opts.report.add(path, fmt.Errorf("%w symlink to the searched directory %s", errSkipped, target))
+		return nil
+	}
+
+	// the link is walked from its target, so a loop back to it stops there
+	return w.walk(path, target)
+}
+
+func (w *walker) inWalked(path string) bool {
+	for _, dir := range w.walked {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *walkReport) add(path string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skipped = append(r.skipped, skippedPath{path: path, err: err})
+}
+
+func (s skippedPath) String() string {
+	var pathErr *fs.PathError
+	if errors.As(s.err, &pathErr) && pathErr.Path == s.path {
+		return "warning: " + s.err.Error()
+	}
+	if errors.Is(s.err, errSkipped) {
+		return s.path + ": " + s.err.Error()
+	}
+	return "warning: " + s.path + ": " + s.err.Error()
+}
+
+// unreadable is the number of paths skipped because of errors
+func (r *walkReport) unreadable() int {
+	count := 0
+	for _, s := range r.skipped {
+		if !errors.Is(s.err, errSkipped) {
+			count++
+		}
+	}
+	return count
+}
+
+// enterCached decides whether the directory is rescanned, subtrees of
+// changed directories are rescanned entirely
+func enterCached(cache *discoveryCache, path string, entry fs.DirEntry, dirs map[string]cachedDir, changed map[string]bool) {
+	info, err := entry.Info()
+	if err != nil {
+		changed[path] = true
+		return
+	}
+
+	dirs[path] = cachedDir{ModTime: info.ModTime()}
+	changed[path] = changed[filepath.Dir(path)] || cache.changed(path, info)
+}
+
+func recordManifest(path string, entry fs.DirEntry, dirs map[string]cachedDir) {
+	info, err := entry.Info()
+	if err != nil {
+		return
+	}
+
+	dir := dirs[filepath.Dir(path)]
+	if dir.Manifests == nil {
+		dir.Manifests = make(map[string]time.Time)
+	}
+	dir.Manifests[entry.Name()] = info.ModTime()
+	dirs[filepath.Dir(path)] = dir
+}
+
+// matchFile finds the detector of the file, files over the size limit are
+// skipped without reading them and cached results are used if the file has
+// not changed
+func matchFile(reg registry, job walkJob, maxSize int64, opts walkOptions) (walkResult, bool) {
+	info, err := job.entry.Info()
+	if err != nil {
+		opts.report.add(job.path, err)
+		return walkResult{}, false
+	}
+	if maxSize > 0 && info.Size() > maxSize {
+		opts.report.add(job.path, fmt.Errorf("%w larger than max_file_size", errSkipped))
+		return walkResult{}, false
+	}
+
+	if job.cached {
+		if file, ok := opts.cache.lookup(job.path, info); ok {
+			return walkResult{seq: job.seq, path: job.path, file: file, matched: file.Extension != ""}, true
+		}
+	}
+
+	result := walkResult{
+		seq:  job.seq,
+		path: job.path,
+		file: cachedFile{ModTime: info.ModTime(), Size: info.Size(), Mode: info.Mode()},
+	}
+	if d, ok := reg.match(job.path, info); ok {
+		result.file.Extension, result.file.Paths = d.Name(), candidatePaths(d, job.path)
+		result.matched = true
+	}
+	return result, true
+}
+
+func confident(stop func(Extension, Filename) bool, result walkResult) bool {
+	for _, path := range result.file.Paths {
+		if stop(result.file.Extension, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// depth is the number of directories between the root and the path, files
+// of the root have depth 1
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
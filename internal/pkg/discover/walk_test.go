@@ -0,0 +1,144 @@
+package discover
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ant1k9/auto-launcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkLimits(t *testing.T) {
+	writeTree(t, map[string]string{
+		"run.sh":               "echo",
+		"big.sh":               "echo " + strings.Repeat("x", 1024),
+		"scripts/deploy.sh":    "echo",
+		"scripts/ci/lint.sh":   "echo",
+		"scripts/ci/a/test.sh": "echo",
+	})
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []Filename
+	}{
+		{
+			name: "unlimited",
+			want: []Filename{"big.sh", "run.sh", "scripts/ci/a/test.sh", "scripts/ci/lint.sh", "scripts/deploy.sh"},
+		},
+		{
+			name: "root only",
+			cfg:  config.Config{MaxDepth: 1},
+			want: []Filename{"big.sh", "run.sh"},
+		},
+		{
+			name: "two levels",
+			cfg:  config.Config{MaxDepth: 2},
+			want: []Filename{"big.sh", "run.sh", "scripts/deploy.sh"},
+		},
+		{
+			name: "small files",
+			cfg:  config.Config{MaxFileSize: 1024},
+			want: []Filename{"run.sh", "scripts/ci/a/test.sh", "scripts/ci/lint.sh", "scripts/deploy.sh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getExecutables(".", tt.cfg)
+			require.NoError(t, err)
+			assert.EqualValues(t, map[Extension][]Filename{BashExtension: tt.want}, got)
+		})
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	const scripts = 200
+	files := map[string]string{"run.sh": "echo"}
+	for i := 0; i < scripts; i++ {
+		files[path.Join("scripts", fmt.Sprintf("%03d.sh", i))] = "echo"
+	}
+	rootPath := writeTree(t, files)
+
+	got, err := walkExecutables(rootPath, config.Config{}, walkOptions{
+		stop: func(_ Extension, p Filename) bool { return p == path.Join(rootPath, "run.sh") },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, path.Join(rootPath, "run.sh"), got[BashExtension][0])
+	assert.Less(t, len(got[BashExtension]), scripts+1)
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	rootPath := writeTree(t, map[string]string{"run.sh": "echo", "lib/tool.sh": "echo"})
+	outsidePath := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(path.Join(outsidePath, "ext.sh"), []byte("echo"), fs.ModePerm))
+	for link, target := range map[string]string{
+		path.Join(rootPath, "alias.sh"):  "run.sh",
+		path.Join(rootPath, "linked"):    "lib",
+		path.Join(rootPath, "lib/loop"):  "..",
+		path.Join(rootPath, "vendored"):  outsidePath,
+		path.Join(outsidePath, "back"):   rootPath,
+		path.Join(rootPath, "broken.sh"): "missing.sh",
+	} {
+		require.NoError(t, os.Symlink(target, link))
+	}
+
+	tests := []struct {
+		name        string
+		follow      bool
+		want        []Filename
+		wantSkipped []string
+	}{
+		{
+			name:        "not followed",
+			want:        []Filename{"alias.sh", "lib/tool.sh", "run.sh"},
+			wantSkipped: []string{"broken.sh", "lib/loop", "linked", "vendored"},
+		},
+		{
+			name:        "followed",
+			follow:      true,
+			want:        []Filename{"alias.sh", "lib/tool.sh", "run.sh", "vendored/ext.sh"},
+			wantSkipped: []string{"broken.sh", "lib/loop", "linked", "vendored/back"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &walkReport{}
+			got, err := walkExecutables(".", config.Config{FollowSymlinks: tt.follow}, walkOptions{report: report})
+			require.NoError(t, err)
+			assert.EqualValues(t, map[Extension][]Filename{BashExtension: tt.want}, got)
+
+			var skipped []string
+			for _, s := range report.skipped {
+				skipped = append(skipped, s.path)
+			}
+			assert.ElementsMatch(t, tt.wantSkipped, skipped)
+			assert.Equal(t, 1, report.unreadable())
+		})
+	}
+}
+
+func TestWalkUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions do not restrict root")
+	}
+
+	rootPath := writeTree(t, map[string]string{"run.sh": "echo", "locked/secret.sh": "echo"})
+	require.NoError(t, os.Chmod(path.Join(rootPath, "locked"), 0))
+	defer os.Chmod(path.Join(rootPath, "locked"), 0755) // nolint: errcheck
+
+	report := &walkReport{}
+	got, err := walkExecutables(rootPath, config.Config{}, walkOptions{report: report})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[Extension][]Filename{BashExtension: {path.Join(rootPath, "run.sh")}}, got)
+	assert.Equal(t, 1, report.unreadable())
+
+	var out strings.Builder
+	printReport(&out, report, false)
+	assert.Equal(t, "warning: 1 path(s) could not be read, run with --verbose to list them\n", out.String())
+}
@@ -0,0 +1,43 @@
+package runfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Find looks for the named run file in dir and its parents like git looks
+// for .git. The search stops at the git repository root or the home
+// directory, whichever comes first. The error wraps os.ErrNotExist if no
+// run file is found.
+func Find(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	home, _ := os.UserHomeDir()
+
+	for start := dir; ; {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if isBoundary(dir, home) || parent == dir {
+			return "", fmt.Errorf("%s in %s and its parents: %w", name, start, os.ErrNotExist)
+		}
+		dir = parent
+	}
+}
+
+func isBoundary(dir, home string) bool {
+	if dir == home {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
@@ -0,0 +1,220 @@
+package runfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/ant1k9/auto-launcher/internal/pkg/utils"
+)
+
+// DefaultTarget is the name given to the command of a legacy plain-script
+// run file when it is read as a structured one
+const DefaultTarget = "default"
+
+var (
+	ErrNoTargets       = errors.New("run file has no targets")
+	ErrNoDefaultTarget = errors.New("no default target")
+	ErrTargetNotFound  = errors.New("target not found")
+	ErrEmptyTargetName = errors.New("empty target name")
+	ErrEmptyCommand    = errors.New("empty command")
+	ErrLastTarget      = errors.New("the last target cannot be removed")
+
+	// targetsTable finds the targets table of a structured run file, which
+	// is broken if it cannot be decoded
+	targetsTable = regexp.MustCompile(`(?m)^[ \t]*\[[ \t]*targets\b`) // nolint: gochecknoglobals
+)
+
+type (
+	// File is a set of named launch targets. It is stored as TOML:
+	//
+	//	default = "server"
+	//
+	//	[targets.server]
+	//	command = "go run ./cmd/server $*"
+	//	description = "HTTP server"
+	//	env = { PORT = "8080" }
+	//
+	//	[targets.migrate]
+	//	command = "./migrate.sh $*"
+	//	dir = "scripts"
+	//
+	// A file that does not decode to targets is a legacy run file: the whole
+	// content is a bash script and becomes the only target, named
+	// DefaultTarget.
+	File struct {
+		// Default is the target launched when no target name is given
+		Default string            `toml:"default,omitempty"`
+		Targets map[string]Target `toml:"targets"`
+
+		legacy bool
+	}
+
+	Target struct {
+		// Command is a bash script, $* expands to the launcher arguments
+		Command string `toml:"command"`
+		// Dir is a working directory relative to the run file location
+		Dir string `toml:"dir,omitempty"`
+		// Env holds extra environment variables for the command
+		Env         map[string]string `toml:"env,omitempty"`
+		Description string            `toml:"description,omitempty"`
+	}
+)
+
+// Read parses the run file at path, falling back to the legacy format
+func Read(path string) (File, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	file, err := Parse(content)
+	if err != nil {
+		return File{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Parse parses run file content, content that does not decode to targets is
+// a legacy script. A file with a targets table that is broken or empty is an
+// error.
+func Parse(content []byte) (File, error) {
+	var file File
+	_, err := toml.Decode(string(content), &file)
+	if err == nil && len(file.Targets) > 0 {
+		return file, nil
+	}
+
+	if targetsTable.Match(content) {
+		if err != nil {
+			return File{}, err
+		}
+		return File{}, ErrNoTargets
+	}
+	return File{
+		Default: DefaultTarget,
+		Targets: map[string]Target{DefaultTarget: {Command: string(content)}},
+		legacy:  true,
+	}, nil
+}
+
+// Legacy reports whether the file was read from a plain bash script
+func (f File) Legacy() bool {
+	return f.legacy
+}
+
+// Names returns target names in alphabetical order
+func (f File) Names() []string {
+	names := make([]string, 0, len(f.Targets))
+	for name := range f.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve picks the target to launch for the command line arguments. If the
+// first argument names a target it is used and stripped from the arguments,
+// otherwise the default target gets all of them.
+func (f File) Resolve(args []string) (string, Target, []string, error) {
+	if len(args) > 0 {
+		if target, ok := f.Targets[args[0]]; ok {
+			return args[0], target, args[1:], nil
+		}
+	}
+
+	name, err := f.defaultName()
+	if err != nil {
+		return "", Target{}, nil, err
+	}
+	return name, f.Targets[name], args, nil
+}
+
+func (f File) defaultName() (string, error) {
+	switch {
+	case len(f.Targets) == 0:
+		return "", ErrNoTargets
+	case f.Default != "":
+		if _, ok := f.Targets[f.Default]; !ok {
+			return "", fmt.Errorf("%w: %s", ErrTargetNotFound, f.Default)
+		}
+		return f.Default, nil
+	case len(f.Targets) == 1:
+		return f.Names()[0], nil
+	default:
+		return "", fmt.Errorf(
+			"%w, choose one of: %s", ErrNoDefaultTarget, strings.Join(f.Names(), ", "),
+		)
+	}
+}
+
+// Add stores the target under the name. The first target of a file becomes
+// the default one.
+func (f *File) Add(name string, target Target) error {
+	if name == "" {
+		return ErrEmptyTargetName
+	}
+	if strings.TrimSpace(target.Command) == "" {
+		return ErrEmptyCommand
+	}
+
+	if f.Targets == nil {
+		f.Targets = make(map[string]Target)
+	}
+	if len(f.Targets) == 0 {
+		f.Default = name
+	}
+	f.Targets[name] = target
+	f.legacy = false
+	return nil
+}
+
+// Remove deletes the target, the default is reset if it pointed to it. A
+// run file keeps at least one target, remove the file instead.
+func (f *File) Remove(name string) error {
+	if _, ok := f.Targets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrTargetNotFound, name)
+	}
+	if len(f.Targets) == 1 {
+		return fmt.Errorf("%w: %s", ErrLastTarget, name)
+	}
+	delete(f.Targets, name)
+	if f.Default == name {
+		f.Default = ""
+	}
+	return nil
+}
+
+// Write stores the file at path. Legacy files are kept as plain scripts.
+func (f File) Write(path string) error {
+	if f.legacy {
+		return ioutil.WriteFile(path, []byte(f.Targets[DefaultTarget].Command), fs.ModePerm)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(f); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), fs.ModePerm)
+}
+
+// Exec runs the target command with bash, baseDir is the run file location
+func (t Target) Exec(baseDir string, args []string) error {
+	env := os.Environ()
+	for key, value := range t.Env {
+		env = append(env, key+"="+value)
+	}
+
+	return utils.RunCommandIn(
+		filepath.Join(baseDir, t.Dir), env,
+		"/usr/bin/env", append([]string{"bash", "-c", t.Command, "auto-launcher"}, args...)...,
+	)
+}
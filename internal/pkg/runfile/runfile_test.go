@@ -0,0 +1,263 @@
+package runfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    File
+		err     error
+	}{
+		{
+			name:    "legacy script",
+			content: "go run main.go $*",
+			want: File{
+				Default: DefaultTarget,
+				Targets: map[string]Target{DefaultTarget: {Command: "go run main.go $*"}},
+				legacy:  true,
+			},
+		},
+		{
+			name:    "legacy multiline script",
+			content: "docker build -t app:local .\ndocker run --rm -ti $* app:local",
+			want: File{
+				Default: DefaultTarget,
+				Targets: map[string]Target{DefaultTarget: {
+					Command: "docker build -t app:local .\ndocker run --rm -ti $* app:local",
+				}},
+				legacy: true,
+			},
+		},
+		{
+			name:    "legacy script with assignments",
+			content: "PORT=8080 go run main.go $*",
+			want: File{
+				Default: DefaultTarget,
+				Targets: map[string]Target{DefaultTarget: {Command: "PORT=8080 go run main.go $*"}},
+				legacy:  true,
+			},
+		},
+		{
+			name: "structured targets",
+			content: `
+default = "server"
+
+[targets.server]
+command = "go run ./cmd/server $*"
+description = "HTTP server"
+env = { PORT = "8080" }
+
+[targets.migrate]
+command = "./migrate.sh $*"
+dir = "scripts"
+`,
+			want: File{
+				Default: "server",
+				Targets: map[string]Target{
+					"server": {
+						Command:     "go run ./cmd/server $*",
+						Description: "HTTP server",
+						Env:         map[string]string{"PORT": "8080"},
+					},
+					"migrate": {Command: "./migrate.sh $*", Dir: "scripts"},
+				},
+			},
+		},
+		{
+			name:    "legacy script with a default variable",
+			content: "set -e\ndefault=\"prod\"\n./deploy.sh $default",
+			want: File{
+				Default: DefaultTarget,
+				Targets: map[string]Target{DefaultTarget: {Command: "set -e\ndefault=\"prod\"\n./deploy.sh $default"}},
+				legacy:  true,
+			},
+		},
+		{
+			name:    "no targets",
+			content: "default = \"server\"\n\n[targets]\n",
+			err:     ErrNoTargets,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.content))
+			require.ErrorIs(t, err, tt.err)
+			assert.EqualValues(t, tt.want, got)
+		})
+	}
+
+	_, err := Parse([]byte("[targets.server]\ncommand = go run .\n"))
+	assert.Error(t, err, "a broken structured file is not a script")
+}
+
+func TestResolve(t *testing.T) {
+	server, worker := Target{Command: "server $*"}, Target{Command: "worker $*"}
+
+	tests := []struct {
+		name       string
+		file       File
+		args       []string
+		wantName   string
+		wantTarget Target
+		wantArgs   []string
+		err        error
+	}{
+		{
+			name:       "named target",
+			file:       File{Default: "server", Targets: map[string]Target{"server": server, "worker": worker}},
+			args:       []string{"worker", "-v"},
+			wantName:   "worker",
+			wantTarget: worker,
+			wantArgs:   []string{"-v"},
+		},
+		{
+			name:       "default target",
+			file:       File{Default: "server", Targets: map[string]Target{"server": server, "worker": worker}},
+			args:       []string{"-v"},
+			wantName:   "server",
+			wantTarget: server,
+			wantArgs:   []string{"-v"},
+		},
+		{
+			name:       "single target",
+			file:       File{Targets: map[string]Target{"worker": worker}},
+			wantName:   "worker",
+			wantTarget: worker,
+		},
+		{
+			name: "no default target",
+			file: File{Targets: map[string]Target{"server": server, "worker": worker}},
+			err:  ErrNoDefaultTarget,
+		},
+		{
+			name: "missing default target",
+			file: File{Default: "migrate", Targets: map[string]Target{"server": server}},
+			err:  ErrTargetNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, target, args, err := tt.file.Resolve(tt.args)
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.wantName, name)
+			assert.EqualValues(t, tt.wantTarget, target)
+			assert.EqualValues(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rootPath, err := ioutil.TempDir("/tmp", "runfile-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootPath)
+	runPath := path.Join(rootPath, ".run")
+
+	file, err := Parse([]byte("make $*"))
+	require.NoError(t, err)
+	require.NoError(t, file.Write(runPath))
+
+	content, err := ioutil.ReadFile(runPath)
+	require.NoError(t, err)
+	assert.Equal(t, "make $*", string(content))
+
+	require.NoError(t, file.Add("worker", Target{Command: "python worker.py $*"}))
+	require.NoError(t, file.Write(runPath))
+
+	got, err := Read(runPath)
+	require.NoError(t, err)
+	assert.False(t, got.Legacy())
+	assert.Equal(t, DefaultTarget, got.Default)
+	assert.Equal(t, []string{DefaultTarget, "worker"}, got.Names())
+
+	require.NoError(t, got.Remove(DefaultTarget))
+	assert.Empty(t, got.Default)
+	assert.ErrorIs(t, got.Remove(DefaultTarget), ErrTargetNotFound)
+	assert.ErrorIs(t, got.Remove("worker"), ErrLastTarget)
+}
+
+func TestExec(t *testing.T) {
+	rootPath, err := ioutil.TempDir("/tmp", "runfile-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootPath)
+
+	file, err := Parse([]byte(`
+[targets.worker]
+command = "printf '%s\\n' \"$@\" > args"
+`))
+	require.NoError(t, err)
+	_, target, args, err := file.Resolve([]string{"worker", "--verbose", "-v", "--", "two words"})
+	require.NoError(t, err)
+	require.NoError(t, target.Exec(rootPath, args))
+
+	content, err := ioutil.ReadFile(path.Join(rootPath, "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "--verbose\n-v\n--\ntwo words\n", string(content))
+}
+
+func TestFind(t *testing.T) {
+	rootPath, err := ioutil.TempDir("/tmp", "runfile-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootPath)
+
+	t.Setenv("HOME", rootPath)
+	repoPath := path.Join(rootPath, "repo")
+	nestedPath := path.Join(repoPath, "internal", "foo")
+	require.NoError(t, os.MkdirAll(nestedPath, 0755))
+	require.NoError(t, os.Mkdir(path.Join(repoPath, ".git"), 0755))
+
+	tests := []struct {
+		name     string
+		runFiles []string
+		dir      string
+		want     string
+	}{
+		{
+			name:     "in the directory",
+			runFiles: []string{nestedPath, repoPath},
+			dir:      nestedPath,
+			want:     path.Join(nestedPath, ".run"),
+		},
+		{
+			name:     "in the git root",
+			runFiles: []string{repoPath},
+			dir:      nestedPath,
+			want:     path.Join(repoPath, ".run"),
+		},
+		{
+			name:     "above the git root",
+			runFiles: []string{rootPath},
+			dir:      nestedPath,
+		},
+		{
+			name:     "in the home directory",
+			runFiles: []string{rootPath},
+			dir:      rootPath,
+			want:     path.Join(rootPath, ".run"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, dir := range tt.runFiles {
+				require.NoError(t, ioutil.WriteFile(path.Join(dir, ".run"), []byte("make $*"), 0644))
+				defer os.Remove(path.Join(dir, ".run"))
+			}
+
+			got, err := Find(tt.dir, ".run")
+			if tt.want == "" {
+				require.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
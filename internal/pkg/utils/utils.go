@@ -4,14 +4,28 @@ import (
 	"log"
 	"os"
 	"os/exec"
+
+	"golang.org/x/term"
 )
 
 func RunCommand(exectutable string, args ...string) error {
+	return RunCommandIn("", nil, exectutable, args...)
+}
+
+// RunCommandIn runs the command in dir with env, empty values mean the
+// current working directory and environment
+func RunCommandIn(dir string, env []string, exectutable string, args ...string) error {
 	cmd := exec.Command(exectutable, args...)
+	cmd.Dir, cmd.Env = dir, env
 	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
 	return cmd.Run()
 }
 
+// IsInteractive reports whether both stdin and stdout are terminals
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 func Must(err error) {
 	if err == nil {
 		return